@@ -1,13 +1,46 @@
 package main
 
 import (
+	"bufio"
+	"compress/gzip"
 	"encoding/json"
+	"errors"
 	"fmt"
-	"io/ioutil"
-	"net/http"
+	"io"
+	"io/fs"
 	"os"
+	"path/filepath"
 	"regexp"
+	"slices"
+	"strconv"
 	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/vcatafesta/chili-big-go/big-aur-packages/internal/index"
+	"github.com/vcatafesta/chili-big-go/big-aur-packages/internal/mirror"
+	"github.com/vcatafesta/chili-big-go/big-aur-packages/internal/query"
+	"vercmp"
+)
+
+const (
+	// URL do arquivo JSON
+	packagesURL = "https://chililinux.com/packages-meta-v1.json.gz"
+	// Diretório da cópia local criada pelo --sync
+	defaultCacheDir = "/var/cache/big-aur-packages"
+)
+
+var (
+	command     string // "search" ou "sync"
+	searchTerms []string
+	queryText   string // --query
+	diffFiles   []string
+	textOutput  bool // --text: tabela em vez de JSON no --diff
+	source      = packagesURL
+	sourceSet   bool // --source informado; senão o sync reusa a fonte do último
+	cacheDir    = defaultCacheDir
+	maxAge      time.Duration
+	noIndex     bool // --no-index: percorre o packages-meta mesmo com índice
 )
 
 // Defina a estrutura para um item no array JSON, ajustando conforme necessário
@@ -32,110 +65,878 @@ type Package struct {
 func printUsage() {
 	fmt.Println("Uso:")
 	fmt.Println("  -Ss, --search <nome do pacote> ...    Nome(s) do pacote(s) para buscar")
-	fmt.Println("  Prefixe 'regex:' antes dos termos para buscar usando expressões regulares.")
+	fmt.Println("  Prefixe 'regex:' antes dos termos para buscar usando expressões regulares,")
+	fmt.Println("  'prefix:' para nomes que começam com o termo e 'word:' para a palavra inteira.")
+	fmt.Println("  --query <consulta>                    Busca com campos e operadores, no lugar dos termos:")
+	fmt.Println("      name:yay OR (maintainer:=bob AND votes:>100 AND NOT outofdate:true)")
+	fmt.Println("      \"web browser\" popularity:>=0.5 votes:10..500 lastmodified:>1700000000")
+	fmt.Println("      Campos: " + strings.Join(queryFieldNames(), ", "))
+	fmt.Println("  --sync                                Baixa/atualiza a cópia local do packages-meta (só se mudou) e o índice de busca")
+	fmt.Println("  --source <url>                        Fonte do sync: http(s), file:// ou lista de mirrors (padrão é " + packagesURL + ")")
+	fmt.Println("  --max-age <tempo>                     Com -Ss, faz o sync antes se a cópia local for mais velha (ex: 3600, 6h)")
+	fmt.Println("  --cache-dir <dir>                     Diretório da cópia local (padrão é " + defaultCacheDir + ")")
+	fmt.Println("  --diff <antigo> <novo>                Compara dois packages-meta: adicionados, removidos, versões,")
+	fmt.Println("                                        mantenedores e marcados como desatualizados (JSON)")
+	fmt.Println("  --text                                Saída do --diff em tabelas de texto")
+	fmt.Println("  --no-index                            Não usa o índice criado pelo --sync (percorre todos os pacotes)")
+	fmt.Println("  Com a cópia local a busca não usa a rede; sem ela, o arquivo é baixado a cada busca.")
+	fmt.Println("  Uma fonte que não termina em .json ou .json.gz é uma lista de mirrors, uma URL por linha.")
 }
 
 func main() {
-	// Captura todos os argumentos da linha de comando
-	args := os.Args[1:]
+	if err := parseArgs(os.Args[1:]); err != nil {
+		fmt.Fprintln(os.Stderr, "Erro:", err)
+		os.Exit(1)
+	}
 
-	// Verifica se o comando principal é -Ss ou --search
-	if len(args) < 1 || (args[0] != "-Ss" && args[0] != "--search") {
+	switch command {
+	case "sync":
+		if err := runSync(); err != nil {
+			fmt.Fprintln(os.Stderr, "Erro no sync:", err)
+			os.Exit(1)
+		}
+	case "diff":
+		if err := runDiff(os.Stdout); err != nil {
+			fmt.Fprintln(os.Stderr, "Erro no diff:", err)
+			os.Exit(1)
+		}
+	case "search":
+		// Verifica se termos de busca foram fornecidos
+		if len(searchTerms) == 0 && queryText == "" {
+			printUsage()
+			return
+		}
+		if len(searchTerms) > 0 && queryText != "" {
+			fmt.Fprintln(os.Stderr, "Erro: use termos de busca ou --query, não os dois")
+			os.Exit(1)
+		}
+		if err := runSearch(os.Stdout); err != nil {
+			var syntaxErr *query.SyntaxError
+			if errors.As(err, &syntaxErr) {
+				// Aponta a coluna do erro embaixo da consulta
+				fmt.Fprintf(os.Stderr, "Erro na consulta: %v\n  %s\n  %s^\n", err, queryText, strings.Repeat(" ", syntaxErr.Col-1))
+			} else {
+				fmt.Fprintln(os.Stderr, "Erro:", err)
+			}
+			os.Exit(1)
+		}
+	default:
 		printUsage()
-		return
 	}
+}
 
-	// Captura todos os termos de busca após o comando principal
-	searchTerms := args[1:]
+// parseArgs lê as opções; o que não é opção é termo de busca
+func parseArgs(args []string) error {
+	value := func(i *int) (string, error) {
+		if *i+1 >= len(args) {
+			return "", fmt.Errorf("%s requer um argumento", args[*i])
+		}
+		*i++
+		return args[*i], nil
+	}
 
-	// Verifica se termos de busca foram fornecidos
-	if len(searchTerms) == 0 {
-		printUsage()
-		return
+	for i := 0; i < len(args); i++ {
+		switch args[i] {
+		case "-Ss", "--search":
+			command = "search"
+		case "--query":
+			v, err := value(&i)
+			if err != nil {
+				return err
+			}
+			command, queryText = "search", v
+		case "--diff":
+			for n := 0; n < 2; n++ {
+				v, err := value(&i)
+				if err != nil {
+					return errors.New("--diff requer o arquivo antigo e o novo")
+				}
+				diffFiles = append(diffFiles, v)
+			}
+			command = "diff"
+		case "--text":
+			textOutput = true
+		case "--json":
+			textOutput = false
+		case "--sync":
+			command = "sync"
+		case "--source":
+			v, err := value(&i)
+			if err != nil {
+				return err
+			}
+			source, sourceSet = v, true
+		case "--cache-dir":
+			v, err := value(&i)
+			if err != nil {
+				return err
+			}
+			cacheDir = v
+		case "--max-age":
+			v, err := value(&i)
+			if err != nil {
+				return err
+			}
+			age, err := parseAge(v)
+			if err != nil {
+				return err
+			}
+			maxAge = age
+		case "--no-index":
+			noIndex = true
+		case "-h", "--help":
+			command = ""
+			return nil
+		default:
+			searchTerms = append(searchTerms, args[i])
+		}
 	}
+	return nil
+}
 
-	// URL do arquivo JSON
-	url := "https://chililinux.com/packages-meta-v1.json.gz"
+// parseAge aceita segundos (3600) ou uma duração do Go (6h, 90m)
+func parseAge(value string) (time.Duration, error) {
+	if seconds, err := strconv.Atoi(value); err == nil && seconds > 0 {
+		return time.Duration(seconds) * time.Second, nil
+	}
+	if age, err := time.ParseDuration(value); err == nil && age > 0 {
+		return age, nil
+	}
+	return 0, fmt.Errorf("--max-age requer segundos ou uma duração como 6h: '%s'", value)
+}
+
+func newMirror() *mirror.Mirror {
+	mir := mirror.New(cacheDir)
+	// Um download truncado ou corrompido não substitui a cópia boa
+	mir.Validate = func(path string) error {
+		f, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		count := 0
+		if err := decodePackages(f, func(Package) error { count++; return nil }); err != nil {
+			return fmt.Errorf("arquivo inválido: %w", err)
+		}
+		if count == 0 {
+			return errors.New("arquivo sem pacotes")
+		}
+		return nil
+	}
+	return mir
+}
+
+// syncSource é a fonte do sync: o --source ou, sem ele, a do último sync
+func syncSource(mir *mirror.Mirror) string {
+	if !sourceSet {
+		if st, err := mir.State(); err == nil && st.Source != "" {
+			return st.Source
+		}
+	}
+	return source
+}
+
+func runSync() error {
+	mir := newMirror()
+	result, err := mir.Sync(syncSource(mir))
+	if err != nil {
+		return err
+	}
+	if result.Updated {
+		fmt.Printf("%s: atualizado de %s (%d bytes)\n", mir.Path(), result.URL, result.Size)
+	} else {
+		fmt.Printf("%s: sem mudanças em %s\n", mir.Path(), result.URL)
+	}
+
+	count, err := updateIndex(mir)
+	if err != nil {
+		return err
+	}
+	if count > 0 {
+		fmt.Printf("%s: %d pacotes\n", filepath.Join(mir.Dir, index.FileName), count)
+	}
+	return nil
+}
+
+// updateIndex gera o índice se ele não existe ou não é da cópia local
+// atual; retorna o número de pacotes indexados, 0 se nada mudou
+func updateIndex(mir *mirror.Mirror) (int, error) {
+	if ix, err := openIndex(mir); err == nil {
+		ix.Close()
+		return 0, nil
+	}
+	count, err := buildIndex(mir)
+	if err != nil {
+		return 0, fmt.Errorf("erro ao criar o índice: %w", err)
+	}
+	return count, nil
+}
+
+// buildIndex gera o índice da cópia local
+func buildIndex(mir *mirror.Mirror) (int, error) {
+	stamp, err := index.StampOf(mir.Path())
+	if err != nil {
+		return 0, err
+	}
+	f, err := mir.Open()
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+
+	w, err := index.Create(mir.Dir)
+	if err != nil {
+		return 0, err
+	}
+	count := 0
+	err = decodePackages(f, func(pkg Package) error {
+		// O registro é o mesmo JSON que a busca sem índice mostra
+		record, err := json.Marshal(pkg)
+		if err != nil {
+			return err
+		}
+		count++
+		return w.Add(pkg.Name, pkg.Description, record)
+	})
+	if err != nil {
+		w.Abort()
+		return 0, err
+	}
+	return count, w.Commit(stamp)
+}
+
+// openIndex abre o índice se ele foi gerado da cópia local atual
+func openIndex(mir *mirror.Mirror) (*index.Index, error) {
+	stamp, err := index.StampOf(mir.Path())
+	if err != nil {
+		return nil, err
+	}
+	ix, err := index.Open(mir.Dir)
+	if err != nil {
+		return nil, err
+	}
+	if !ix.Source().ModTime.Equal(stamp.ModTime) || ix.Source().Size != stamp.Size {
+		ix.Close()
+		return nil, errors.New("índice desatualizado")
+	}
+	return ix, nil
+}
+
+// runSearch pesquisa na cópia local, sincronizando antes se o --max-age
+// pedir; sem cópia local, lê direto da fonte
+func runSearch(w io.Writer) error {
+	m := newMatcher(searchTerms)
+	var f filter = m
+	if queryText != "" {
+		q, err := newQueryFilter(queryText)
+		if err != nil {
+			return err
+		}
+		f = q
+	}
+	mir := newMirror()
+
+	if maxAge > 0 {
+		st, err := mir.State()
+		if err != nil || time.Since(st.Synced) > maxAge {
+			// Em caso de erro segue com a cópia antiga, se houver
+			if _, err := mir.Sync(syncSource(mir)); err != nil {
+				fmt.Fprintln(os.Stderr, "Erro no sync:", err)
+			} else if _, err := updateIndex(mir); err != nil {
+				fmt.Fprintln(os.Stderr, "Erro no sync:", err)
+			}
+		}
+	}
 
-	// Faz o download do arquivo JSON
-	resp, err := http.Get(url)
+	// O índice só cobre nome e descrição; o --query percorre os pacotes
+	if !noIndex && queryText == "" {
+		if ix, err := openIndex(mir); err == nil {
+			defer ix.Close()
+			return searchIndex(ix, m, w)
+		}
+	}
+
+	r, err := openPackages(mir)
 	if err != nil {
-		fmt.Println("Erro ao fazer a requisição:", err)
-		return
+		return err
+	}
+	defer r.Close()
+
+	// Os pacotes são filtrados e mostrados à medida que chegam, sem guardar
+	// a lista inteira (~100 mil pacotes) na memória
+	if err := searchPackages(r, f, w); err != nil {
+		return fmt.Errorf("erro ao decodificar o JSON: %w", err)
 	}
-	defer resp.Body.Close()
+	return nil
+}
 
-	// Lê o conteúdo do arquivo JSON
-	data, err := ioutil.ReadAll(resp.Body)
+// openPackages abre a cópia local ou, se ainda não houve sync, o primeiro
+// arquivo da fonte que responder
+func openPackages(mir *mirror.Mirror) (io.ReadCloser, error) {
+	f, err := mir.Open()
+	if err == nil || !errors.Is(err, fs.ErrNotExist) {
+		return f, err
+	}
+	urls, err := mir.URLs(source)
 	if err != nil {
-		fmt.Println("Erro ao ler o arquivo:", err)
-		return
+		return nil, err
 	}
+	var errs []error
+	for _, u := range urls {
+		body, err := mir.Get(u)
+		if err == nil {
+			return body, nil
+		}
+		errs = append(errs, fmt.Errorf("%s: %w", u, err))
+	}
+	return nil, errors.Join(errs...)
+}
+
+// filter decide quais pacotes a busca mostra
+type filter interface {
+	match(pkg Package) bool
+}
 
-	// Decodifica o JSON
-	var packages []Package
-	if err := json.Unmarshal(data, &packages); err != nil {
-		fmt.Println("Erro ao decodificar o JSON:", err)
-		return
+// queryFields são os campos do --query; os sinônimos ficam no mesmo case
+// do queryFilter.match
+var queryFields = query.Schema{
+	"":               query.Text, // nome ou descrição
+	"name":           query.Text,
+	"desc":           query.Text,
+	"description":    query.Text,
+	"base":           query.Text,
+	"packagebase":    query.Text,
+	"version":        query.Text,
+	"url":            query.Text,
+	"maintainer":     query.Text,
+	"submitter":      query.Text,
+	"prefix":         query.Prefix, // início do nome
+	"word":           query.Word,   // palavra inteira no nome ou na descrição
+	"regex":          query.Regexp,
+	"id":             query.Number,
+	"votes":          query.Number,
+	"numvotes":       query.Number,
+	"popularity":     query.Number,
+	"firstsubmitted": query.Number,
+	"lastmodified":   query.Number,
+	"outofdate":      query.Bool,
+	"orphan":         query.Bool, // sem mantenedor
+}
+
+func queryFieldNames() []string {
+	var names []string
+	for name := range queryFields {
+		if name != "" {
+			names = append(names, name)
+		}
 	}
+	slices.Sort(names)
+	return names
+}
+
+// queryFilter avalia a consulta do --query
+type queryFilter struct {
+	root query.Node
+}
+
+func newQueryFilter(text string) (*queryFilter, error) {
+	root, err := query.Parse(text, queryFields)
+	if err != nil {
+		return nil, err
+	}
+	return &queryFilter{root: root}, nil
+}
 
-	// Prepara termos de busca
-	var regexTerms []*regexp.Regexp
-	var normalTerms []string
+func (q *queryFilter) match(pkg Package) bool {
+	var words []string // só calculadas se a consulta tiver word:
+	return q.root.Eval(func(t *query.Term) bool {
+		switch t.Field {
+		case "":
+			return t.MatchText(pkg.Name) || t.MatchText(pkg.Description)
+		case "name":
+			return t.MatchText(pkg.Name)
+		case "desc", "description":
+			return t.MatchText(pkg.Description)
+		case "base", "packagebase":
+			return t.MatchText(pkg.PackageBase)
+		case "version":
+			return t.MatchText(pkg.Version)
+		case "url":
+			return t.MatchText(pkg.URL)
+		case "maintainer":
+			return t.MatchText(pkg.Maintainer)
+		case "submitter":
+			return t.MatchText(pkg.Submitter)
+		case "prefix":
+			return strings.HasPrefix(strings.ToLower(pkg.Name), t.Text)
+		case "word":
+			if words == nil {
+				words = index.Words(pkg.Name + " " + pkg.Description)
+			}
+			return slices.Contains(words, t.Text)
+		case "regex":
+			return t.Re.MatchString(pkg.Name) || t.Re.MatchString(pkg.Description)
+		case "id":
+			return t.Compare(float64(pkg.ID))
+		case "votes", "numvotes":
+			return t.Compare(float64(pkg.NumVotes))
+		case "popularity":
+			return t.Compare(pkg.Popularity)
+		case "firstsubmitted":
+			return t.Compare(float64(pkg.FirstSubmitted))
+		case "lastmodified":
+			return t.Compare(float64(pkg.LastModified))
+		case "outofdate":
+			return (pkg.OutOfDate != nil) == t.Bool
+		case "orphan":
+			return (pkg.Maintainer == "") == t.Bool
+		}
+		return false
+	})
+}
 
+// matcher guarda os termos de busca já preparados: os normais, 'prefix:' e
+// 'word:' em minúsculas e os 'regex:' compilados
+type matcher struct {
+	normalTerms []string
+	prefixTerms []string
+	wordTerms   []string
+	regexTerms  []*regexp.Regexp
+}
+
+func newMatcher(searchTerms []string) *matcher {
+	m := &matcher{}
 	for _, term := range searchTerms {
-		if strings.HasPrefix(term, "regex:") {
-			// Remove o prefixo 'regex:'
-			pattern := term[len("regex:"):]
+		if pattern, ok := strings.CutPrefix(term, "regex:"); ok {
 			re, err := regexp.Compile(pattern)
 			if err != nil {
-				fmt.Printf("Erro ao compilar regex '%s': %v\n", pattern, err)
+				fmt.Fprintf(os.Stderr, "Erro ao compilar regex '%s': %v\n", pattern, err)
 				continue
 			}
-			regexTerms = append(regexTerms, re)
+			m.regexTerms = append(m.regexTerms, re)
+		} else if prefix, ok := strings.CutPrefix(term, "prefix:"); ok {
+			m.prefixTerms = append(m.prefixTerms, strings.ToLower(prefix))
+		} else if word, ok := strings.CutPrefix(term, "word:"); ok {
+			m.wordTerms = append(m.wordTerms, strings.ToLower(word))
 		} else {
-			normalTerms = append(normalTerms, term)
+			m.normalTerms = append(m.normalTerms, strings.ToLower(term))
 		}
 	}
+	return m
+}
 
-	// Filtra os pacotes com base nos termos de busca
-	var output []Package
-	for _, pkg := range packages {
-		matched := false
-		for _, term := range normalTerms {
-			if strings.Contains(strings.ToLower(pkg.Name), strings.ToLower(term)) ||
-				strings.Contains(strings.ToLower(pkg.Description), strings.ToLower(term)) {
-				matched = true
-				break
-			}
+// match diz se o pacote coincide com algum termo: substring ou regex: no
+// nome ou na descrição, prefix: no início do nome, word: como palavra
+// inteira no nome ou na descrição
+func (m *matcher) match(pkg Package) bool {
+	name, desc := strings.ToLower(pkg.Name), strings.ToLower(pkg.Description)
+	for _, term := range m.normalTerms {
+		if strings.Contains(name, term) || strings.Contains(desc, term) {
+			return true
 		}
-		for _, re := range regexTerms {
-			if re.MatchString(pkg.Name) || re.MatchString(pkg.Description) {
-				matched = true
-				break
+	}
+	for _, prefix := range m.prefixTerms {
+		if strings.HasPrefix(name, prefix) {
+			return true
+		}
+	}
+	if len(m.wordTerms) > 0 {
+		words := index.Words(pkg.Name + " " + pkg.Description)
+		for _, word := range m.wordTerms {
+			if slices.Contains(words, word) {
+				return true
 			}
 		}
-		if matched {
-			output = append(output, pkg)
+	}
+	for _, re := range m.regexTerms {
+		if re.MatchString(pkg.Name) || re.MatchString(pkg.Description) {
+			return true
 		}
 	}
+	return false
+}
 
-	// Começa o array JSON
-	fmt.Print("[\n")
+// lookup retorna, na ordem do packages-meta, os pacotes do índice que
+// coincidem com algum termo
+func (m *matcher) lookup(ix *index.Index) ([]int32, error) {
+	var lists [][]int32
+	add := func(ids []int32, err error) error {
+		lists = append(lists, ids)
+		return err
+	}
+	for _, term := range m.normalTerms {
+		if err := add(ix.Substring(term)); err != nil {
+			return nil, err
+		}
+	}
+	for _, prefix := range m.prefixTerms {
+		if err := add(ix.Prefix(prefix)); err != nil {
+			return nil, err
+		}
+	}
+	for _, word := range m.wordTerms {
+		if err := add(ix.Word(word)); err != nil {
+			return nil, err
+		}
+	}
+	for _, re := range m.regexTerms {
+		if err := add(ix.Regexp(re)); err != nil {
+			return nil, err
+		}
+	}
+	return index.Union(lists...), nil
+}
 
-	// Exibe a saída JSON formatada com um pacote por linha
-	for i, pkg := range output {
-		if i > 0 {
-			fmt.Print(",\n")
+// jsonArray escreve um array JSON com um elemento por linha
+type jsonArray struct {
+	w     io.Writer
+	count int
+}
+
+func (a *jsonArray) begin() {
+	fmt.Fprint(a.w, "[\n")
+}
+
+func (a *jsonArray) add(elem []byte) error {
+	if a.count > 0 {
+		fmt.Fprint(a.w, ",\n")
+	}
+	a.count++
+	_, err := a.w.Write(elem)
+	return err
+}
+
+func (a *jsonArray) end() {
+	fmt.Fprint(a.w, "\n]\n")
+}
+
+// searchPackages escreve em w o array JSON com os pacotes de r que coincidem
+// com m, um pacote por linha, assim que cada um é decodificado
+func searchPackages(r io.Reader, m filter, w io.Writer) error {
+	out := &jsonArray{w: w}
+	out.begin()
+	err := decodePackages(r, func(pkg Package) error {
+		if !m.match(pkg) {
+			return nil
 		}
 		pkgJSON, err := json.Marshal(pkg)
 		if err != nil {
-			fmt.Println("Erro ao formatar o JSON:", err)
-			continue
+			return err
+		}
+		return out.add(pkgJSON)
+	})
+	out.end()
+	return err
+}
+
+// searchIndex faz o mesmo que o searchPackages usando o índice
+func searchIndex(ix *index.Index, m *matcher, w io.Writer) error {
+	ids, err := m.lookup(ix)
+	if err != nil {
+		return fmt.Errorf("erro ao ler o índice: %w", err)
+	}
+	out := &jsonArray{w: w}
+	out.begin()
+	defer out.end()
+	for _, id := range ids {
+		record, err := ix.Record(id)
+		if err != nil {
+			return err
+		}
+		if err := out.add(record); err != nil {
+			return err
 		}
-		fmt.Print(string(pkgJSON))
 	}
+	return nil
+}
+
+// diffEntry é o que o --diff guarda de cada pacote do arquivo antigo
+type diffEntry struct {
+	ID         int
+	Name       string
+	Version    string
+	Maintainer string
+	OutOfDate  bool
+	matched    bool
+}
+
+// diffPackage é um pacote adicionado, removido ou marcado como desatualizado
+type diffPackage struct {
+	ID         int    `json:"ID"`
+	Name       string `json:"Name"`
+	Version    string `json:"Version"`
+	Maintainer string `json:"Maintainer"`
+	OutOfDate  int64  `json:"OutOfDate,omitempty"`
+}
+
+type versionChange struct {
+	ID         int    `json:"ID"`
+	Name       string `json:"Name"`
+	OldVersion string `json:"OldVersion"`
+	NewVersion string `json:"NewVersion"`
+	Downgrade  bool   `json:"Downgrade,omitempty"` // versão nova menor que a antiga (vercmp)
+}
+
+type maintainerChange struct {
+	ID            int    `json:"ID"`
+	Name          string `json:"Name"`
+	OldMaintainer string `json:"OldMaintainer"`
+	NewMaintainer string `json:"NewMaintainer"`
+}
+
+// diffReport é a saída do --diff; as listas vêm ordenadas pelo nome
+type diffReport struct {
+	Old         string             `json:"old"`
+	New         string             `json:"new"`
+	Summary     map[string]int     `json:"summary"`
+	Added       []diffPackage      `json:"added"`
+	Removed     []diffPackage      `json:"removed"`
+	Versions    []versionChange    `json:"versions"`
+	Maintainers []maintainerChange `json:"maintainers"`
+	Flagged     []diffPackage      `json:"flagged"`
+}
+
+func runDiff(w io.Writer) error {
+	report, err := diffSnapshots(diffFiles[0], diffFiles[1])
+	if err != nil {
+		return err
+	}
+	if textOutput {
+		printDiffText(w, report)
+		return nil
+	}
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintln(w, string(data))
+	return err
+}
+
+// diffSnapshots compara dois packages-meta (arquivos, file:// ou http). Só
+// o antigo fica na memória, e só com os campos comparados; o novo é lido em
+// streaming. Os pacotes são casados pelo ID ou, se o ID não existir no
+// antigo, pelo nome (pacote apagado e enviado de novo).
+func diffSnapshots(oldSource, newSource string) (diffReport, error) {
+	report := diffReport{
+		Old:         oldSource,
+		New:         newSource,
+		Added:       []diffPackage{},
+		Removed:     []diffPackage{},
+		Versions:    []versionChange{},
+		Maintainers: []maintainerChange{},
+		Flagged:     []diffPackage{},
+	}
+	mir := newMirror()
+
+	byID := make(map[int]*diffEntry)
+	byName := make(map[string]*diffEntry)
+	err := readSnapshot(mir, oldSource, func(pkg Package) error {
+		entry := &diffEntry{
+			ID:         pkg.ID,
+			Name:       pkg.Name,
+			Version:    pkg.Version,
+			Maintainer: pkg.Maintainer,
+			OutOfDate:  pkg.OutOfDate != nil,
+		}
+		byID[pkg.ID] = entry
+		byName[pkg.Name] = entry
+		return nil
+	})
+	if err != nil {
+		return report, fmt.Errorf("%s: %w", oldSource, err)
+	}
+
+	err = readSnapshot(mir, newSource, func(pkg Package) error {
+		old, ok := byID[pkg.ID]
+		if !ok || old.matched {
+			old, ok = byName[pkg.Name]
+		}
+		if !ok || old.matched {
+			report.Added = append(report.Added, newDiffPackage(pkg))
+			return nil
+		}
+		old.matched = true
 
-	// Fecha o array JSON
-	fmt.Print("\n]\n")
+		if old.Version != pkg.Version {
+			report.Versions = append(report.Versions, versionChange{
+				ID:         pkg.ID,
+				Name:       pkg.Name,
+				OldVersion: old.Version,
+				NewVersion: pkg.Version,
+				Downgrade:  vercmp.Compare(pkg.Version, old.Version) < 0,
+			})
+		}
+		if old.Maintainer != pkg.Maintainer {
+			report.Maintainers = append(report.Maintainers, maintainerChange{
+				ID:            pkg.ID,
+				Name:          pkg.Name,
+				OldMaintainer: old.Maintainer,
+				NewMaintainer: pkg.Maintainer,
+			})
+		}
+		if !old.OutOfDate && pkg.OutOfDate != nil {
+			report.Flagged = append(report.Flagged, newDiffPackage(pkg))
+		}
+		return nil
+	})
+	if err != nil {
+		return report, fmt.Errorf("%s: %w", newSource, err)
+	}
+
+	for _, old := range byID {
+		if !old.matched {
+			report.Removed = append(report.Removed, diffPackage{
+				ID:         old.ID,
+				Name:       old.Name,
+				Version:    old.Version,
+				Maintainer: old.Maintainer,
+			})
+		}
+	}
+
+	byPackageName := func(a, b diffPackage) int { return strings.Compare(a.Name, b.Name) }
+	slices.SortFunc(report.Added, byPackageName)
+	slices.SortFunc(report.Removed, byPackageName)
+	slices.SortFunc(report.Flagged, byPackageName)
+	slices.SortFunc(report.Versions, func(a, b versionChange) int { return strings.Compare(a.Name, b.Name) })
+	slices.SortFunc(report.Maintainers, func(a, b maintainerChange) int { return strings.Compare(a.Name, b.Name) })
+	report.Summary = map[string]int{
+		"added":       len(report.Added),
+		"removed":     len(report.Removed),
+		"versions":    len(report.Versions),
+		"maintainers": len(report.Maintainers),
+		"flagged":     len(report.Flagged),
+	}
+	return report, nil
+}
+
+func newDiffPackage(pkg Package) diffPackage {
+	entry := diffPackage{ID: pkg.ID, Name: pkg.Name, Version: pkg.Version, Maintainer: pkg.Maintainer}
+	if ts, ok := pkg.OutOfDate.(float64); ok {
+		entry.OutOfDate = int64(ts)
+	}
+	return entry
+}
+
+// readSnapshot chama fn para cada pacote do packages-meta em source
+func readSnapshot(mir *mirror.Mirror, source string, fn func(Package) error) error {
+	r, err := mir.Get(source)
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+	return decodePackages(r, fn)
+}
+
+// printDiffText mostra o diffReport em tabelas, uma por tipo de mudança
+func printDiffText(w io.Writer, report diffReport) {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	sections := 0
+	section := func(title string, count int, header string) bool {
+		if count == 0 {
+			return false
+		}
+		if sections > 0 {
+			fmt.Fprintln(tw)
+		}
+		sections++
+		fmt.Fprintf(tw, ":: %s (%d)\n%s\n", title, count, header)
+		return true
+	}
+	date := func(ts int64) string {
+		return time.Unix(ts, 0).UTC().Format(time.DateOnly)
+	}
+
+	if section("Adicionados", len(report.Added), "NOME\tVERSÃO\tMANTENEDOR") {
+		for _, pkg := range report.Added {
+			fmt.Fprintf(tw, "%s\t%s\t%s\n", pkg.Name, pkg.Version, orNone(pkg.Maintainer))
+		}
+	}
+	if section("Removidos", len(report.Removed), "NOME\tVERSÃO\tMANTENEDOR") {
+		for _, pkg := range report.Removed {
+			fmt.Fprintf(tw, "%s\t%s\t%s\n", pkg.Name, pkg.Version, orNone(pkg.Maintainer))
+		}
+	}
+	if section("Versões", len(report.Versions), "NOME\tANTES\tDEPOIS") {
+		for _, change := range report.Versions {
+			line := change.Name + "\t" + change.OldVersion + "\t" + change.NewVersion
+			if change.Downgrade {
+				line += "\t(downgrade)"
+			}
+			fmt.Fprintln(tw, line)
+		}
+	}
+	if section("Mantenedores", len(report.Maintainers), "NOME\tANTES\tDEPOIS") {
+		for _, change := range report.Maintainers {
+			fmt.Fprintf(tw, "%s\t%s\t%s\n", change.Name, orNone(change.OldMaintainer), orNone(change.NewMaintainer))
+		}
+	}
+	if section("Marcados como desatualizados", len(report.Flagged), "NOME\tVERSÃO\tDESDE") {
+		for _, pkg := range report.Flagged {
+			fmt.Fprintf(tw, "%s\t%s\t%s\n", pkg.Name, pkg.Version, date(pkg.OutOfDate))
+		}
+	}
+	tw.Flush()
+
+	if sections > 0 {
+		fmt.Fprintln(w)
+	}
+	fmt.Fprintf(w, "%d adicionados, %d removidos, %d versões, %d mantenedores, %d desatualizados\n",
+		len(report.Added), len(report.Removed), len(report.Versions), len(report.Maintainers), len(report.Flagged))
+}
+
+// orNone mostra o mantenedor vazio dos pacotes órfãos
+func orNone(maintainer string) string {
+	if maintainer == "" {
+		return "(órfão)"
+	}
+	return maintainer
+}
+
+// decodePackages lê o array JSON do packages-meta-v1.json, compactado com
+// gzip ou não, e chama fn para cada pacote, um de cada vez
+func decodePackages(r io.Reader, fn func(Package) error) error {
+	r, err := gunzip(r)
+	if err != nil {
+		return err
+	}
+
+	dec := json.NewDecoder(r)
+	if err := expectDelim(dec, '['); err != nil {
+		return err
+	}
+	for dec.More() {
+		var pkg Package
+		if err := dec.Decode(&pkg); err != nil {
+			return err
+		}
+		if err := fn(pkg); err != nil {
+			return err
+		}
+	}
+	return expectDelim(dec, ']')
+}
+
+// gunzip descompacta r se ele começar com o número mágico do gzip: o
+// net/http só descompacta sozinho quando o servidor manda Content-Encoding,
+// e o .json.gz pode vir como um arquivo comum
+func gunzip(r io.Reader) (io.Reader, error) {
+	br := bufio.NewReader(r)
+	magic, err := br.Peek(2)
+	if err == nil && magic[0] == 0x1f && magic[1] == 0x8b {
+		return gzip.NewReader(br)
+	}
+	return br, nil
+}
+
+func expectDelim(dec *json.Decoder, delim json.Delim) error {
+	tok, err := dec.Token()
+	if err != nil {
+		return err
+	}
+	if tok != delim {
+		return fmt.Errorf("esperado '%s', encontrado %v", delim, tok)
+	}
+	return nil
 }
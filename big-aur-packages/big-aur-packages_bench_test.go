@@ -0,0 +1,216 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/vcatafesta/chili-big-go/big-aur-packages/internal/index"
+	"github.com/vcatafesta/chili-big-go/big-aur-packages/internal/mirror"
+)
+
+// Comparação da busca linear com a busca no índice sobre um packages-meta
+// sintético do tamanho do real (~100 mil pacotes):
+//
+//	go test -run '^$' -bench . -benchmem
+
+const benchPackages = 100000
+
+var benchWords = strings.Fields(`aur git bin python go rust qt gtk kde gnome
+	lib font theme plugin cli daemon server client wrapper helper browser
+	editor player audio video image network tool kernel driver firmware
+	icon cursor shell terminal manager viewer converter library bindings`)
+
+// syntheticMeta gera um packages-meta-v1.json.gz com n pacotes
+func syntheticMeta(tb testing.TB, n int) []byte {
+	tb.Helper()
+	rng := rand.New(rand.NewSource(1))
+	word := func() string { return benchWords[rng.Intn(len(benchWords))] }
+
+	var buf bytes.Buffer
+	buf.WriteString("[")
+	for i := 0; i < n; i++ {
+		if i > 0 {
+			buf.WriteString(",")
+		}
+		pkg := Package{
+			ID:          i + 1,
+			Name:        fmt.Sprintf("%s-%s-%d", word(), word(), i),
+			Version:     "1.0-1",
+			Description: strings.Join([]string{word(), word(), word(), word(), word(), word()}, " "),
+			NumVotes:    rng.Intn(1000),
+			Popularity:  rng.Float64(),
+		}
+		data, err := json.Marshal(pkg)
+		if err != nil {
+			tb.Fatal(err)
+		}
+		buf.Write(data)
+	}
+	buf.WriteString("]")
+	return gzipBytes(tb, buf.String())
+}
+
+// syntheticMirror grava o packages-meta sintético como cópia local, já com
+// o índice
+func syntheticMirror(tb testing.TB, n int) *mirror.Mirror {
+	tb.Helper()
+	mir := mirror.New(tb.TempDir())
+	if err := os.WriteFile(mir.Path(), syntheticMeta(tb, n), 0o644); err != nil {
+		tb.Fatal(err)
+	}
+	if _, err := buildIndex(mir); err != nil {
+		tb.Fatal(err)
+	}
+	return mir
+}
+
+var benchQueries = map[string][]string{
+	"substring": {"wrapper"},
+	"short":     {"go"},
+	"prefix":    {"prefix:kernel-"},
+	"word":      {"word:firmware"},
+	"regex":     {"regex:^rust-.*-9+$"},
+	"rare":      {"player-icon-9999"},
+}
+
+// TestIndexMatchesLinear confere se o índice e a busca linear dão a mesma
+// saída
+func TestIndexMatchesLinear(t *testing.T) {
+	mir := syntheticMirror(t, 5000)
+	ix, err := openIndex(mir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ix.Close()
+
+	queries := [][]string{{"Wrapper", "prefix:GO-"}, {"a"}, {"word:aur", "regex:-1$"}, {"nada-disso"}}
+	for _, terms := range benchQueries {
+		queries = append(queries, terms)
+	}
+	for _, terms := range queries {
+		var linear, indexed bytes.Buffer
+		f, err := mir.Open()
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = searchPackages(f, newMatcher(terms), &linear)
+		f.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := searchIndex(ix, newMatcher(terms), &indexed); err != nil {
+			t.Fatal(err)
+		}
+		if linear.String() != indexed.String() {
+			t.Errorf("%q: índice (%d bytes) difere da busca linear (%d bytes)", terms, indexed.Len(), linear.Len())
+		}
+	}
+
+	// Um sync que troca a cópia local invalida o índice
+	if err := os.WriteFile(mir.Path(), syntheticMeta(t, 10), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := openIndex(mir); err == nil {
+		t.Error("índice desatualizado foi aberto")
+	}
+	if count, err := updateIndex(mir); err != nil || count != 10 {
+		t.Errorf("updateIndex = %d, %v", count, err)
+	}
+	if _, err := os.Stat(filepath.Join(mir.Dir, index.FileName)); err != nil {
+		t.Error(err)
+	}
+}
+
+// BenchmarkLinear é a busca sem índice: gunzip e JSON de todos os pacotes
+func BenchmarkLinear(b *testing.B) {
+	mir := syntheticMirror(b, benchPackages)
+	for name, terms := range benchQueries {
+		b.Run(name, func(b *testing.B) {
+			m := newMatcher(terms)
+			for i := 0; i < b.N; i++ {
+				f, err := mir.Open()
+				if err != nil {
+					b.Fatal(err)
+				}
+				if err := searchPackages(f, m, io.Discard); err != nil {
+					b.Fatal(err)
+				}
+				f.Close()
+			}
+		})
+	}
+}
+
+// BenchmarkLinearDecoded é só o laço de filtro com os pacotes já em
+// memória, o custo dos strings.ToLower a cada busca
+func BenchmarkLinearDecoded(b *testing.B) {
+	var packages []Package
+	err := decodePackages(bytes.NewReader(syntheticMeta(b, benchPackages)), func(pkg Package) error {
+		packages = append(packages, pkg)
+		return nil
+	})
+	if err != nil {
+		b.Fatal(err)
+	}
+	for name, terms := range benchQueries {
+		b.Run(name, func(b *testing.B) {
+			m := newMatcher(terms)
+			for i := 0; i < b.N; i++ {
+				for _, pkg := range packages {
+					m.match(pkg)
+				}
+			}
+		})
+	}
+}
+
+// BenchmarkIndex é a busca no índice já aberto, incluindo a leitura dos
+// registros encontrados
+func BenchmarkIndex(b *testing.B) {
+	ix, err := openIndex(syntheticMirror(b, benchPackages))
+	if err != nil {
+		b.Fatal(err)
+	}
+	defer ix.Close()
+	for name, terms := range benchQueries {
+		b.Run(name, func(b *testing.B) {
+			m := newMatcher(terms)
+			for i := 0; i < b.N; i++ {
+				if err := searchIndex(ix, m, io.Discard); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+// BenchmarkIndexOpen é o custo fixo de cada execução com índice
+func BenchmarkIndexOpen(b *testing.B) {
+	mir := syntheticMirror(b, benchPackages)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		ix, err := openIndex(mir)
+		if err != nil {
+			b.Fatal(err)
+		}
+		ix.Close()
+	}
+}
+
+// BenchmarkIndexBuild é o custo extra do --sync
+func BenchmarkIndexBuild(b *testing.B) {
+	mir := syntheticMirror(b, benchPackages)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := buildIndex(mir); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
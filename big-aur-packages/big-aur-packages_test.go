@@ -0,0 +1,280 @@
+package main
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+const testPackages = `[
+{"ID":1,"Name":"yay","Description":"Yet another yogurt","NumVotes":2000,"Popularity":25.5,"OutOfDate":null,"Maintainer":"jguer"},
+{"ID":2,"Name":"paru","Description":"Feature packed AUR helper","NumVotes":1500,"Popularity":18.2,"OutOfDate":null,"Maintainer":"Morganamilo"},
+{"ID":3,"Name":"google-chrome","Description":"The popular web browser","NumVotes":3000,"Popularity":0.4,"OutOfDate":1700000000,"Maintainer":""}
+]`
+
+func gzipBytes(t testing.TB, data string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	if _, err := zw.Write([]byte(data)); err != nil {
+		t.Fatal(err)
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestDecodePackages(t *testing.T) {
+	inputs := map[string][]byte{
+		"json":    []byte(testPackages),
+		"json.gz": gzipBytes(t, testPackages),
+	}
+	for name, data := range inputs {
+		var got []string
+		err := decodePackages(bytes.NewReader(data), func(pkg Package) error {
+			got = append(got, pkg.Name)
+			return nil
+		})
+		if err != nil || strings.Join(got, " ") != "yay paru google-chrome" {
+			t.Errorf("%s: pacotes %q, erro %v", name, got, err)
+		}
+	}
+
+	for _, bad := range []string{`{"ID":1}`, `[{"ID":1},`, `[{"ID":"x"}]`} {
+		if err := decodePackages(strings.NewReader(bad), func(Package) error { return nil }); err == nil {
+			t.Errorf("%q: não retornou erro", bad)
+		}
+	}
+}
+
+// TestSearchPackagesStreaming confere se um pacote é mostrado antes do fim
+// do download
+func TestSearchPackagesStreaming(t *testing.T) {
+	pr, pw := io.Pipe()
+	out := make(chan string, 1)
+	w := &chanWriter{ch: out}
+	done := make(chan error)
+	go func() {
+		done <- searchPackages(pr, newMatcher([]string{"yay"}), w)
+	}()
+
+	go pw.Write([]byte(`[{"ID":1,"Name":"yay"},`))
+	if got := <-out; got != "[\n" {
+		t.Fatalf("início %q", got)
+	}
+	if got := <-out; !strings.Contains(got, `"Name":"yay"`) {
+		t.Fatalf("pacote %q", got)
+	}
+	go func() {
+		pw.Write([]byte(`{"ID":2,"Name":"paru"}]`))
+		pw.Close()
+	}()
+	if got := <-out; got != "\n]\n" {
+		t.Errorf("fim %q", got)
+	}
+	if err := <-done; err != nil {
+		t.Error(err)
+	}
+}
+
+type chanWriter struct{ ch chan string }
+
+func (w *chanWriter) Write(p []byte) (int, error) {
+	w.ch <- string(p)
+	return len(p), nil
+}
+
+func TestMatcher(t *testing.T) {
+	cases := []struct {
+		terms []string
+		want  string
+	}{
+		{[]string{"YAY"}, "yay"},
+		{[]string{"browser", "paru"}, "paru google-chrome"},
+		{[]string{"regex:^(yay|paru)$"}, "yay paru"},
+		{[]string{"regex:["}, ""},
+	}
+	for _, c := range cases {
+		var buf bytes.Buffer
+		if err := searchPackages(strings.NewReader(testPackages), newMatcher(c.terms), &buf); err != nil {
+			t.Fatal(err)
+		}
+		var packages []Package
+		if err := json.Unmarshal(buf.Bytes(), &packages); err != nil {
+			t.Fatalf("%q: saída inválida %q: %v", c.terms, buf.String(), err)
+		}
+		var got []string
+		for _, pkg := range packages {
+			got = append(got, pkg.Name)
+		}
+		if strings.Join(got, " ") != c.want {
+			t.Errorf("%q: pacotes %q, esperado %q", c.terms, got, c.want)
+		}
+	}
+}
+
+// setArgs aplica parseArgs e restaura as opções no fim do teste
+func setArgs(t *testing.T, args ...string) {
+	t.Helper()
+	oldSource, oldCacheDir := source, cacheDir
+	t.Cleanup(func() {
+		command, searchTerms, queryText, maxAge, noIndex = "", nil, "", 0, false
+		diffFiles, textOutput = nil, false
+		source, sourceSet, cacheDir = oldSource, false, oldCacheDir
+	})
+	if err := parseArgs(args); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestParseArgs(t *testing.T) {
+	setArgs(t, "-Ss", "yay", "--max-age", "6h", "regex:^paru$", "--cache-dir", "/tmp/x")
+	if command != "search" || strings.Join(searchTerms, " ") != "yay regex:^paru$" || maxAge != 6*time.Hour || cacheDir != "/tmp/x" {
+		t.Errorf("command=%q termos=%q maxAge=%v cacheDir=%q", command, searchTerms, maxAge, cacheDir)
+	}
+	if age, err := parseAge("3600"); err != nil || age != time.Hour {
+		t.Errorf("parseAge(3600) = %v, %v", age, err)
+	}
+	for _, bad := range [][]string{{"--max-age", "ontem"}, {"--max-age", "-1h"}, {"--sync", "--source"}} {
+		if err := parseArgs(bad); err == nil {
+			t.Errorf("parseArgs(%q) não retornou erro", bad)
+		}
+	}
+}
+
+// TestSearchMirror confere se o --max-age sincroniza e se a busca seguinte
+// usa a cópia local mesmo sem a fonte
+func TestSearchMirror(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "packages-meta-v1.json.gz")
+	if err := os.WriteFile(file, gzipBytes(t, testPackages), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	setArgs(t, "-Ss", "paru", "--max-age", "1h", "--source", "file://"+file, "--cache-dir", filepath.Join(dir, "cache"))
+
+	var buf bytes.Buffer
+	if err := runSearch(&buf); err != nil || !strings.Contains(buf.String(), `"Name":"paru"`) {
+		t.Fatalf("busca com sync: %q, %v", buf.String(), err)
+	}
+	if err := os.Remove(file); err != nil {
+		t.Fatal(err)
+	}
+	buf.Reset()
+	if err := runSearch(&buf); err != nil || !strings.Contains(buf.String(), `"Name":"paru"`) {
+		t.Fatalf("busca offline: %q, %v", buf.String(), err)
+	}
+
+	// Sync que não passa no Validate
+	if err := os.WriteFile(file, []byte("[]"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := runSync(); err == nil {
+		t.Error("sync de um arquivo sem pacotes não falhou")
+	}
+}
+
+func TestQueryFilter(t *testing.T) {
+	cases := []struct{ query, want string }{
+		{`yay`, "yay"},
+		{`name:yay OR maintainer:=morganamilo`, "yay paru"},
+		{`votes:>1500 NOT outofdate:true`, "yay"},
+		{`popularity:>=18.2 popularity:<25.5`, "paru"},
+		{`"web browser" OR word:aur`, "paru google-chrome"},
+		{`(prefix:go OR prefix:pa) AND votes:1000..2000`, "paru"},
+		{`orphan:true OR regex:"^y(a|o)"`, "yay google-chrome"},
+		{`NOT id:2`, "yay google-chrome"},
+	}
+	for _, c := range cases {
+		q, err := newQueryFilter(c.query)
+		if err != nil {
+			t.Fatalf("%q: %v", c.query, err)
+		}
+		var buf bytes.Buffer
+		if err := searchPackages(strings.NewReader(testPackages), q, &buf); err != nil {
+			t.Fatal(err)
+		}
+		var packages []Package
+		if err := json.Unmarshal(buf.Bytes(), &packages); err != nil {
+			t.Fatal(err)
+		}
+		var got []string
+		for _, pkg := range packages {
+			got = append(got, pkg.Name)
+		}
+		if strings.Join(got, " ") != c.want {
+			t.Errorf("%q: pacotes %q, esperado %q", c.query, got, c.want)
+		}
+	}
+
+	for _, bad := range []string{`maintaner:bob`, `prefix:=yay`, `word:=aur`, `word:qt5-base`} {
+		if _, err := newQueryFilter(bad); err == nil {
+			t.Errorf("%q aceito", bad)
+		}
+	}
+}
+
+func TestDiffSnapshots(t *testing.T) {
+	dir := t.TempDir()
+	oldFile := filepath.Join(dir, "old.json.gz")
+	newFile := filepath.Join(dir, "new.json")
+	oldMeta := `[
+{"ID":1,"Name":"yay","Version":"12.3.5-1","Maintainer":"jguer","OutOfDate":null},
+{"ID":2,"Name":"paru","Version":"2.0.3-1","Maintainer":"Morganamilo","OutOfDate":null},
+{"ID":3,"Name":"google-chrome","Version":"129.0-1","Maintainer":"","OutOfDate":null},
+{"ID":4,"Name":"yaourt","Version":"1.9-1","Maintainer":"","OutOfDate":1500000000},
+{"ID":5,"Name":"foo-git","Version":"r10.abc-1","Maintainer":"bob","OutOfDate":null}
+]`
+	newMeta := `[
+{"ID":1,"Name":"yay","Version":"12.4.0-1","Maintainer":"jguer","OutOfDate":null},
+{"ID":2,"Name":"paru","Version":"2.0.3-1","Maintainer":"alice","OutOfDate":1760000000},
+{"ID":3,"Name":"google-chrome","Version":"128.0-1","Maintainer":"","OutOfDate":null},
+{"ID":9,"Name":"foo-git","Version":"r10.abc-1","Maintainer":"bob","OutOfDate":null},
+{"ID":10,"Name":"bar","Version":"1.0-1","Maintainer":"carol","OutOfDate":null}
+]`
+	if err := os.WriteFile(oldFile, gzipBytes(t, oldMeta), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(newFile, []byte(newMeta), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	report, err := diffSnapshots(oldFile, "file://"+newFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, _ := json.Marshal(report.Summary)
+	if string(data) != `{"added":1,"flagged":1,"maintainers":1,"removed":1,"versions":2}` {
+		t.Errorf("summary %s", data)
+	}
+	if report.Added[0].Name != "bar" || report.Removed[0].Name != "yaourt" {
+		t.Errorf("added %+v, removed %+v", report.Added, report.Removed)
+	}
+	if v := report.Versions; v[0].Name != "google-chrome" || !v[0].Downgrade || v[1].Name != "yay" || v[1].Downgrade {
+		t.Errorf("versions %+v", v)
+	}
+	if m := report.Maintainers[0]; m.Name != "paru" || m.OldMaintainer != "Morganamilo" || m.NewMaintainer != "alice" {
+		t.Errorf("maintainers %+v", report.Maintainers)
+	}
+	if f := report.Flagged[0]; f.Name != "paru" || f.OutOfDate != 1760000000 {
+		t.Errorf("flagged %+v", report.Flagged)
+	}
+
+	var buf bytes.Buffer
+	printDiffText(&buf, report)
+	for _, want := range []string{":: Adicionados (1)", "google-chrome  129.0-1   128.0-1  (downgrade)\n", "yaourt  1.9-1   (órfão)\n", "paru  Morganamilo  alice\n", "paru  2.0.3-1  2025-10-09\n"} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("falta %q em\n%s", want, buf.String())
+		}
+	}
+
+	if _, err := diffSnapshots(oldFile, filepath.Join(dir, "nao-existe.json")); err == nil {
+		t.Error("arquivo inexistente não retornou erro")
+	}
+}
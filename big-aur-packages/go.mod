@@ -0,0 +1,7 @@
+module github.com/vcatafesta/chili-big-go/big-aur-packages
+
+go 1.23.0
+
+require vercmp v0.0.0
+
+replace vercmp => ../vercmp
@@ -0,0 +1,740 @@
+/*
+  index.go - índice de busca do packages-meta para o big-aur-packages
+    Chili GNU/Linux - https://github.com/vcatafesta/chili/go
+    Chili GNU/Linux - https://chililinux.com
+    Chili GNU/Linux - https://chilios.com.br
+
+  Created: 2026/10/17
+  Altered: 2026/10/17
+
+  Copyright (c) 2024-2026, Vilmar Catafesta <vcatafesta@gmail.com>
+  All rights reserved.
+
+  Redistribution and use in source and binary forms, with or without
+  modification, are permitted provided that the following conditions
+  are met:
+  1. Redistributions of source code must retain the above copyright
+    notice, this list of conditions and the following disclaimer.
+  2. Redistributions in binary form must reproduce the above copyright
+    notice, this list of conditions and the following disclaimer in the
+    documentation and/or other materials provided with the distribution.
+
+  THIS SOFTWARE IS PROVIDED BY THE AUTHOR ``AS IS'' AND ANY EXPRESS OR
+  IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED WARRANTIES
+  OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE DISCLAIMED.
+  IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR ANY DIRECT, INDIRECT,
+  INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING, BUT
+  NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE,
+  DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY
+  THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT
+  (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF
+  THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+*/
+
+// Package index é o índice de busca do big-aur-packages, gerado no --sync a
+// partir da cópia local do packages-meta.
+//
+// Há três listas invertidas: trigramas (busca por substring), palavras
+// (busca por palavra inteira) e nomes ordenados (busca por prefixo). A busca
+// por substring intersecta as listas dos trigramas do termo e só então
+// confere os candidatos com strings.Contains.
+//
+// Tudo fica em um arquivo só, trocado de forma atômica (temporário +
+// rename):
+//
+//	registros   JSON de cada pacote, um após o outro
+//	textos      nome e descrição originais de cada pacote
+//	diretório   início do registro e do texto de cada pacote (uint64)
+//	nomes       nomes em minúsculas, em ordem, com o número do pacote
+//	tabela      início de cada nome ordenado (uint64)
+//	postings    listas dos trigramas e das palavras (int32)
+//	cabeçalho   gob com a posição das seções e as chaves das listas
+//	trailer     8 bytes com a posição do cabeçalho
+//
+// O Open decodifica só o cabeçalho; o resto é lido sob demanda com ReadAt,
+// de modo que a memória de uma busca não cresce com o número de pacotes
+// (a não ser pelos resultados).
+package index
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"encoding/gob"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+)
+
+// FileName é o nome do índice no diretório da cópia local.
+const FileName = "packages.idx"
+
+// version muda quando o formato do arquivo muda; índices de outra versão são
+// ignorados até o próximo --sync.
+const version = 2
+
+var errCorrupt = errors.New("índice corrompido")
+
+// Stamp identifica o arquivo de onde o índice foi gerado.
+type Stamp struct {
+	Size    int64
+	ModTime time.Time
+}
+
+// StampOf retorna o Stamp do arquivo path.
+func StampOf(path string) (Stamp, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return Stamp{}, err
+	}
+	return Stamp{Size: info.Size(), ModTime: info.ModTime().UTC()}, nil
+}
+
+// header é a parte do índice gravada em gob, a única mantida na memória. As
+// chaves das listas invertidas ficam achatadas (chaves ordenadas e início de
+// cada lista), que o gob decodifica bem mais rápido que um map.
+type header struct {
+	Version int
+	Source  Stamp
+	Count   int // número de pacotes
+
+	// Início de cada seção no arquivo
+	Texts     int64
+	Dir       int64
+	Names     int64
+	NameTable int64
+	GramDocs  int64
+	WordDocs  int64
+	End       int64 // fim da última seção, início do cabeçalho
+
+	Grams     []uint32
+	GramStart []int32 // em postings, a partir de GramDocs
+
+	WordText  string  // palavras em ordem, concatenadas
+	WordEnd   []int32 // fim de cada palavra em WordText
+	WordStart []int32 // em postings, a partir de WordDocs
+}
+
+// Index é um índice aberto.
+type Index struct {
+	header
+	f *os.File
+}
+
+// Len é o número de pacotes.
+func (ix *Index) Len() int {
+	return ix.Count
+}
+
+// Source é o Stamp do arquivo de onde o índice foi gerado.
+func (ix *Index) Source() Stamp {
+	return ix.header.Source
+}
+
+// Open abre o índice de dir.
+func Open(dir string) (*Index, error) {
+	f, err := os.Open(filepath.Join(dir, FileName))
+	if err != nil {
+		return nil, err
+	}
+	ix, err := load(f)
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	return ix, nil
+}
+
+func load(f *os.File) (*Index, error) {
+	info, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+	var trailer [8]byte
+	if info.Size() < int64(len(trailer)) {
+		return nil, errors.New("índice truncado")
+	}
+	end := info.Size() - int64(len(trailer))
+	if _, err := f.ReadAt(trailer[:], end); err != nil {
+		return nil, err
+	}
+	start := int64(binary.LittleEndian.Uint64(trailer[:]))
+	if start < 0 || start > end {
+		return nil, errCorrupt
+	}
+
+	ix := &Index{f: f}
+	dec := gob.NewDecoder(bufio.NewReader(io.NewSectionReader(f, start, end-start)))
+	if err := dec.Decode(&ix.header); err != nil {
+		return nil, err
+	}
+	if ix.Version != version {
+		return nil, errors.New("índice de outra versão")
+	}
+	if !ix.valid(start) {
+		return nil, errCorrupt
+	}
+	return ix, nil
+}
+
+// valid confere se as seções estão em ordem e com os tamanhos esperados.
+func (ix *Index) valid(start int64) bool {
+	h := &ix.header
+	n := int64(h.Count)
+	sections := []int64{0, h.Texts, h.Dir, h.Names, h.NameTable, h.GramDocs, h.WordDocs, h.End, start}
+	return n >= 0 &&
+		slices.IsSorted(sections) &&
+		h.Names-h.Dir == 16*(n+1) &&
+		h.GramDocs-h.NameTable == 8*(n+1) &&
+		len(h.GramStart) == len(h.Grams)+1 &&
+		len(h.WordStart) == len(h.WordEnd)+1 &&
+		h.WordDocs-h.GramDocs == 4*int64(h.GramStart[len(h.Grams)]) &&
+		h.End-h.WordDocs == 4*int64(h.WordStart[len(h.WordEnd)]) &&
+		h.End == start
+}
+
+// Close fecha o arquivo do índice.
+func (ix *Index) Close() error {
+	return ix.f.Close()
+}
+
+// readAt lê n bytes a partir de off.
+func (ix *Index) readAt(off int64, n int64) ([]byte, error) {
+	buf := make([]byte, n)
+	if _, err := ix.f.ReadAt(buf, off); err != nil {
+		return nil, err
+	}
+	return buf, nil
+}
+
+// dir retorna o início e o fim do registro e do texto do pacote id.
+func (ix *Index) dir(id int32) (record, text [2]int64, err error) {
+	if id < 0 || int(id) >= ix.Count {
+		return record, text, errCorrupt
+	}
+	buf, err := ix.readAt(ix.Dir+16*int64(id), 32)
+	if err != nil {
+		return record, text, err
+	}
+	record = [2]int64{int64(binary.LittleEndian.Uint64(buf[0:])), int64(binary.LittleEndian.Uint64(buf[16:]))}
+	text = [2]int64{int64(binary.LittleEndian.Uint64(buf[8:])), int64(binary.LittleEndian.Uint64(buf[24:]))}
+	if record[0] > record[1] || text[0] > text[1] {
+		return record, text, errCorrupt
+	}
+	return record, text, nil
+}
+
+// Record retorna o registro JSON do pacote id.
+func (ix *Index) Record(id int32) ([]byte, error) {
+	record, _, err := ix.dir(id)
+	if err != nil {
+		return nil, err
+	}
+	return ix.readAt(record[0], record[1]-record[0])
+}
+
+// text retorna o nome e a descrição originais do pacote id.
+func (ix *Index) text(id int32) ([]byte, []byte, error) {
+	_, text, err := ix.dir(id)
+	if err != nil {
+		return nil, nil, err
+	}
+	buf, err := ix.readAt(text[0], text[1]-text[0])
+	if err != nil {
+		return nil, nil, err
+	}
+	r := bytes.NewReader(buf)
+	name, err := readBytes(r, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	desc, err := readBytes(r, nil)
+	return name, desc, err
+}
+
+// scanTexts chama fn com o nome e a descrição de cada pacote, lendo a seção
+// de textos em sequência. Os bytes passados a fn são reaproveitados na
+// chamada seguinte.
+func (ix *Index) scanTexts(fn func(id int32, name, desc []byte)) error {
+	r := bufio.NewReader(io.NewSectionReader(ix.f, ix.Texts, ix.Dir-ix.Texts))
+	var name, desc []byte
+	var err error
+	for id := range int32(ix.Count) {
+		if name, err = readBytes(r, name); err != nil {
+			return err
+		}
+		if desc, err = readBytes(r, desc); err != nil {
+			return err
+		}
+		fn(id, name, desc)
+	}
+	return nil
+}
+
+// eachText chama fn com o nome e a descrição de cada pacote de ids (em ordem
+// crescente). Com muitos pacotes, percorrer a seção de textos em sequência
+// sai mais barato que um ReadAt por pacote.
+func (ix *Index) eachText(ids []int32, fn func(id int32, name, desc []byte)) error {
+	if len(ids) > ix.Count/16 {
+		next := 0
+		return ix.scanTexts(func(id int32, name, desc []byte) {
+			if next < len(ids) && ids[next] == id {
+				next++
+				fn(id, name, desc)
+			}
+		})
+	}
+	for _, id := range ids {
+		name, desc, err := ix.text(id)
+		if err != nil {
+			return err
+		}
+		fn(id, name, desc)
+	}
+	return nil
+}
+
+// postings lê as posições [start, end) da seção de postings que começa em
+// section.
+func (ix *Index) postings(section int64, start, end int32) ([]int32, error) {
+	buf, err := ix.readAt(section+4*int64(start), 4*int64(end-start))
+	if err != nil {
+		return nil, err
+	}
+	ids := make([]int32, end-start)
+	for i := range ids {
+		ids[i] = int32(binary.LittleEndian.Uint32(buf[4*i:]))
+	}
+	return ids, nil
+}
+
+// Substring retorna os pacotes com term (já em minúsculas) no nome ou na
+// descrição.
+func (ix *Index) Substring(term string) ([]int32, error) {
+	var ids []int32
+	var lower []byte
+	contains := func(id int32, name, desc []byte) {
+		for _, text := range [][]byte{name, desc} {
+			lower = appendLower(lower[:0], text)
+			if bytes.Contains(lower, []byte(term)) {
+				ids = append(ids, id)
+				return
+			}
+		}
+	}
+
+	if len(term) < 3 {
+		err := ix.scanTexts(contains)
+		return ids, err
+	}
+	grams := trigrams(term)
+	lists := make([][]int32, 0, len(grams))
+	for _, g := range grams {
+		docs, err := ix.gramDocs(g)
+		if err != nil {
+			return nil, err
+		}
+		lists = append(lists, docs)
+	}
+	candidates := intersect(lists)
+	if len(term) == 3 {
+		// Um trigrama só: a lista já é a resposta exata
+		return candidates, nil
+	}
+	err := ix.eachText(candidates, contains)
+	return ids, err
+}
+
+// Prefix retorna os pacotes cujo nome começa com prefix (já em minúsculas).
+func (ix *Index) Prefix(prefix string) ([]int32, error) {
+	var searchErr error
+	i := sort.Search(ix.Count, func(i int) bool {
+		if searchErr != nil {
+			return true
+		}
+		_, name, err := ix.sortedName(i)
+		if err != nil {
+			searchErr = err
+			return true
+		}
+		return name >= prefix
+	})
+	if searchErr != nil || i == ix.Count {
+		return nil, searchErr
+	}
+
+	table, err := ix.readAt(ix.NameTable+8*int64(i), 8)
+	if err != nil {
+		return nil, err
+	}
+	start := int64(binary.LittleEndian.Uint64(table))
+	if start < ix.Names || start > ix.NameTable {
+		return nil, errCorrupt
+	}
+	r := bufio.NewReader(io.NewSectionReader(ix.f, start, ix.NameTable-start))
+	var ids []int32
+	for ; i < ix.Count; i++ {
+		id, name, err := readName(r)
+		if err != nil {
+			return nil, err
+		}
+		if !strings.HasPrefix(name, prefix) {
+			break
+		}
+		ids = append(ids, id)
+	}
+	slices.Sort(ids)
+	return ids, nil
+}
+
+// sortedName retorna o i-ésimo nome (em minúsculas) na ordem alfabética.
+func (ix *Index) sortedName(i int) (int32, string, error) {
+	table, err := ix.readAt(ix.NameTable+8*int64(i), 16)
+	if err != nil {
+		return 0, "", err
+	}
+	start, end := int64(binary.LittleEndian.Uint64(table)), int64(binary.LittleEndian.Uint64(table[8:]))
+	if start < ix.Names || start > end || end > ix.NameTable {
+		return 0, "", errCorrupt
+	}
+	buf, err := ix.readAt(start, end-start)
+	if err != nil {
+		return 0, "", err
+	}
+	return readName(bytes.NewReader(buf))
+}
+
+// Word retorna os pacotes que têm a palavra word (já em minúsculas) no nome
+// ou na descrição, como separada por Words.
+func (ix *Index) Word(word string) ([]int32, error) {
+	i, found := sort.Find(len(ix.WordEnd), func(i int) int {
+		return strings.Compare(word, ix.word(i))
+	})
+	if !found {
+		return nil, nil
+	}
+	return ix.postings(ix.WordDocs, ix.WordStart[i], ix.WordStart[i+1])
+}
+
+func (ix *Index) word(i int) string {
+	start := int32(0)
+	if i > 0 {
+		start = ix.WordEnd[i-1]
+	}
+	return ix.WordText[start:ix.WordEnd[i]]
+}
+
+// Regexp retorna os pacotes cujo nome ou descrição original coincide com re;
+// percorre todos os pacotes, mas só a seção de textos, sem decodificar o
+// JSON.
+func (ix *Index) Regexp(re *regexp.Regexp) ([]int32, error) {
+	var ids []int32
+	err := ix.scanTexts(func(id int32, name, desc []byte) {
+		if re.Match(name) || re.Match(desc) {
+			ids = append(ids, id)
+		}
+	})
+	return ids, err
+}
+
+func (ix *Index) gramDocs(g uint32) ([]int32, error) {
+	i, found := slices.BinarySearch(ix.Grams, g)
+	if !found {
+		return nil, nil
+	}
+	return ix.postings(ix.GramDocs, ix.GramStart[i], ix.GramStart[i+1])
+}
+
+// Union junta listas ordenadas de pacotes, sem repetições.
+func Union(lists ...[]int32) []int32 {
+	var ids []int32
+	for _, list := range lists {
+		ids = append(ids, list...)
+	}
+	slices.Sort(ids)
+	return slices.Compact(ids)
+}
+
+// intersect retorna os pacotes presentes em todas as listas ordenadas,
+// começando pela menor.
+func intersect(lists [][]int32) []int32 {
+	if len(lists) == 0 {
+		return nil
+	}
+	slices.SortFunc(lists, func(a, b []int32) int { return len(a) - len(b) })
+	ids := slices.Clone(lists[0])
+	for _, list := range lists[1:] {
+		n := 0
+		for _, id := range ids {
+			if _, found := slices.BinarySearch(list, id); found {
+				ids[n] = id
+				n++
+			}
+		}
+		ids = ids[:n]
+	}
+	return ids
+}
+
+// Words separa s em palavras minúsculas (letras e dígitos).
+func Words(s string) []string {
+	return strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// trigrams retorna os trigramas de bytes de s, sem repetições.
+func trigrams(s string) []uint32 {
+	var grams []uint32
+	for i := 0; i+3 <= len(s); i++ {
+		grams = append(grams, uint32(s[i])<<16|uint32(s[i+1])<<8|uint32(s[i+2]))
+	}
+	slices.Sort(grams)
+	return slices.Compact(grams)
+}
+
+// Os textos são gravados como tamanho (uvarint) seguido dos bytes, e cada
+// nome ordenado como o número do pacote (int32) seguido do texto.
+
+func appendString(buf []byte, s string) []byte {
+	buf = binary.AppendUvarint(buf, uint64(len(s)))
+	return append(buf, s...)
+}
+
+type byteReader interface {
+	io.Reader
+	io.ByteReader
+}
+
+// readBytes lê um texto, reaproveitando buf se couber.
+func readBytes(r byteReader, buf []byte) ([]byte, error) {
+	n, err := binary.ReadUvarint(r)
+	if err != nil {
+		return nil, err
+	}
+	if n > 1<<20 {
+		return nil, errCorrupt
+	}
+	buf = slices.Grow(buf[:0], int(n))[:n]
+	if _, err := io.ReadFull(r, buf); err != nil {
+		return nil, err
+	}
+	return buf, nil
+}
+
+func readName(r byteReader) (int32, string, error) {
+	var id [4]byte
+	if _, err := io.ReadFull(r, id[:]); err != nil {
+		return 0, "", err
+	}
+	name, err := readBytes(r, nil)
+	return int32(binary.LittleEndian.Uint32(id[:])), string(name), err
+}
+
+// appendLower acrescenta a dst o texto em minúsculas, como strings.ToLower,
+// sem alocar quando o texto é ASCII.
+func appendLower(dst, text []byte) []byte {
+	for _, c := range text {
+		if c >= utf8.RuneSelf {
+			return append(dst, bytes.ToLower(text)...)
+		}
+	}
+	for _, c := range text {
+		if 'A' <= c && c <= 'Z' {
+			c += 'a' - 'A'
+		}
+		dst = append(dst, c)
+	}
+	return dst
+}
+
+// Writer grava um índice novo; nada muda em dir até o Commit.
+type Writer struct {
+	dir     string
+	tmp     *os.File
+	w       *bufio.Writer
+	off     int64
+	records []int64 // início do registro de cada pacote
+	texts   []byte  // seção de textos, gravada no Commit
+	textOff []int64 // início do texto de cada pacote em texts
+	names   []string
+	grams   map[uint32][]int32
+	words   map[string][]int32
+}
+
+// Create começa um índice novo em dir.
+func Create(dir string) (*Writer, error) {
+	tmp, err := os.CreateTemp(dir, ".index-*")
+	if err != nil {
+		return nil, err
+	}
+	return &Writer{
+		dir:   dir,
+		tmp:   tmp,
+		w:     bufio.NewWriter(tmp),
+		grams: make(map[uint32][]int32),
+		words: make(map[string][]int32),
+	}, nil
+}
+
+// Add acrescenta um pacote; os pacotes são numerados na ordem do Add.
+func (w *Writer) Add(name, desc string, record []byte) error {
+	id := int32(len(w.records))
+	lowerName, lowerDesc := strings.ToLower(name), strings.ToLower(desc)
+	w.records = append(w.records, w.off)
+	w.textOff = append(w.textOff, int64(len(w.texts)))
+	w.texts = appendString(appendString(w.texts, name), desc)
+	w.names = append(w.names, lowerName)
+
+	for _, g := range trigrams(lowerName + "\x00" + lowerDesc) {
+		w.grams[g] = append(w.grams[g], id)
+	}
+	for _, word := range Words(name + " " + desc) {
+		if docs := w.words[word]; len(docs) == 0 || docs[len(docs)-1] != id {
+			w.words[word] = append(docs, id)
+		}
+	}
+
+	n, err := w.w.Write(record)
+	w.off += int64(n)
+	return err
+}
+
+// Commit grava o índice e substitui o de dir.
+func (w *Writer) Commit(source Stamp) error {
+	defer os.Remove(w.tmp.Name())
+
+	err := w.finish(source)
+	if err == nil {
+		err = w.w.Flush()
+	}
+	if cerr := w.tmp.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil {
+		err = os.Chmod(w.tmp.Name(), 0o644)
+	}
+	if err == nil {
+		err = os.Rename(w.tmp.Name(), filepath.Join(w.dir, FileName))
+	}
+	return err
+}
+
+// Abort descarta o índice em construção.
+func (w *Writer) Abort() {
+	w.tmp.Close()
+	os.Remove(w.tmp.Name())
+}
+
+// write grava buf depois das seções anteriores.
+func (w *Writer) write(buf []byte) error {
+	n, err := w.w.Write(buf)
+	w.off += int64(n)
+	return err
+}
+
+// finish grava, depois dos registros, as demais seções, o cabeçalho e o
+// trailer.
+func (w *Writer) finish(source Stamp) error {
+	n := len(w.records)
+	h := header{Version: version, Source: source, Count: n}
+	var buf []byte
+
+	// Textos e diretório
+	h.Texts = w.off
+	if err := w.write(w.texts); err != nil {
+		return err
+	}
+	h.Dir = w.off
+	w.records = append(w.records, h.Texts)
+	w.textOff = append(w.textOff, int64(len(w.texts)))
+	for i := range n + 1 {
+		buf = binary.LittleEndian.AppendUint64(buf, uint64(w.records[i]))
+		buf = binary.LittleEndian.AppendUint64(buf, uint64(h.Texts+w.textOff[i]))
+	}
+	if err := w.write(buf); err != nil {
+		return err
+	}
+
+	// Nomes em ordem alfabética e a tabela com o início de cada um
+	order := make([]int32, n)
+	for i := range order {
+		order[i] = int32(i)
+	}
+	sort.SliceStable(order, func(i, j int) bool { return w.names[order[i]] < w.names[order[j]] })
+	h.Names = w.off
+	table := make([]byte, 0, 8*(n+1))
+	buf = buf[:0]
+	for _, id := range order {
+		table = binary.LittleEndian.AppendUint64(table, uint64(h.Names+int64(len(buf))))
+		buf = binary.LittleEndian.AppendUint32(buf, uint32(id))
+		buf = appendString(buf, w.names[id])
+	}
+	table = binary.LittleEndian.AppendUint64(table, uint64(h.Names+int64(len(buf))))
+	if err := w.write(buf); err != nil {
+		return err
+	}
+	h.NameTable = w.off
+	if err := w.write(table); err != nil {
+		return err
+	}
+
+	// Postings dos trigramas
+	h.GramDocs = w.off
+	h.Grams = make([]uint32, 0, len(w.grams))
+	for g := range w.grams {
+		h.Grams = append(h.Grams, g)
+	}
+	slices.Sort(h.Grams)
+	buf = buf[:0]
+	for _, g := range h.Grams {
+		h.GramStart = append(h.GramStart, int32(len(buf)/4))
+		for _, id := range w.grams[g] {
+			buf = binary.LittleEndian.AppendUint32(buf, uint32(id))
+		}
+	}
+	h.GramStart = append(h.GramStart, int32(len(buf)/4))
+	if err := w.write(buf); err != nil {
+		return err
+	}
+
+	// Postings das palavras
+	h.WordDocs = w.off
+	words := make([]string, 0, len(w.words))
+	for word := range w.words {
+		words = append(words, word)
+	}
+	slices.Sort(words)
+	var wordText strings.Builder
+	buf = buf[:0]
+	for _, word := range words {
+		wordText.WriteString(word)
+		h.WordEnd = append(h.WordEnd, int32(wordText.Len()))
+		h.WordStart = append(h.WordStart, int32(len(buf)/4))
+		for _, id := range w.words[word] {
+			buf = binary.LittleEndian.AppendUint32(buf, uint32(id))
+		}
+	}
+	h.WordStart = append(h.WordStart, int32(len(buf)/4))
+	h.WordText = wordText.String()
+	if err := w.write(buf); err != nil {
+		return err
+	}
+
+	h.End = w.off
+	if err := gob.NewEncoder(w.w).Encode(&h); err != nil {
+		return err
+	}
+	var trailer [8]byte
+	binary.LittleEndian.PutUint64(trailer[:], uint64(h.End))
+	_, err := w.w.Write(trailer[:])
+	return err
+}
@@ -0,0 +1,127 @@
+package index
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"testing"
+	"time"
+)
+
+var testDocs = []struct{ name, desc string }{
+	{"yay", "Yet another yogurt. Pacman wrapper and AUR helper written in go."},
+	{"paru", "Feature packed AUR helper"},
+	{"google-chrome", "The popular web browser by Google (Stable Channel)"},
+	{"Yaourt", "A pacman wrapper with extended features"},
+	{"go-yq", "Portable command-line YAML processor"},
+}
+
+func buildTest(t *testing.T) (*Index, string) {
+	t.Helper()
+	dir := t.TempDir()
+	w, err := Create(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, doc := range testDocs {
+		if err := w.Add(doc.name, doc.desc, []byte(`{"Name":"`+doc.name+`"}`)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	stamp := Stamp{Size: 42, ModTime: time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC)}
+	if err := w.Commit(stamp); err != nil {
+		t.Fatal(err)
+	}
+	ix, err := Open(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { ix.Close() })
+	if ix.Len() != len(testDocs) || ix.Source() != stamp {
+		t.Fatalf("Len=%d Source=%+v", ix.Len(), ix.Source())
+	}
+	return ix, dir
+}
+
+func TestSearch(t *testing.T) {
+	ix, _ := buildTest(t)
+	cases := []struct {
+		kind, term string
+		want       string
+	}{
+		{"substring", "wrapper", "[0 3]"},
+		{"substring", "aur helper", "[0 1]"},
+		{"substring", "yo", "[0]"},
+		{"substring", "y", "[0 2 3 4]"},
+		{"substring", "chrome", "[2]"},
+		{"substring", "wra", "[0 3]"},
+		{"substring", "nada-disso", "[]"},
+		{"prefix", "ya", "[0 3]"},
+		{"prefix", "go", "[2 4]"},
+		{"prefix", "zzz", "[]"},
+		{"prefix", "yaourt", "[3]"},
+		{"prefix", "", "[0 1 2 3 4]"},
+		{"word", "go", "[0 4]"},
+		{"word", "aur", "[0 1]"},
+		{"word", "chrom", "[]"},
+		{"regex", "^Y", "[0 3]"},
+	}
+	for _, c := range cases {
+		var ids []int32
+		var err error
+		switch c.kind {
+		case "substring":
+			ids, err = ix.Substring(c.term)
+		case "prefix":
+			ids, err = ix.Prefix(c.term)
+		case "word":
+			ids, err = ix.Word(c.term)
+		case "regex":
+			ids, err = ix.Regexp(regexp.MustCompile(c.term))
+		}
+		if err != nil {
+			t.Errorf("%s %q: %v", c.kind, c.term, err)
+		}
+		if got := fmt.Sprint(ids); got != c.want {
+			t.Errorf("%s %q = %s, esperado %s", c.kind, c.term, got, c.want)
+		}
+	}
+
+	for id, doc := range testDocs {
+		record, err := ix.Record(int32(id))
+		if err != nil || string(record) != `{"Name":"`+doc.name+`"}` {
+			t.Errorf("Record(%d) = %q, %v", id, record, err)
+		}
+	}
+	if got := fmt.Sprint(Union([]int32{0, 3}, []int32{1, 3}, nil)); got != "[0 1 3]" {
+		t.Errorf("Union = %s", got)
+	}
+}
+
+func TestOpenInvalid(t *testing.T) {
+	_, dir := buildTest(t)
+	path := filepath.Join(dir, FileName)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, bad := range [][]byte{data[:4], data[:len(data)-3], append([]byte("x"), data...)} {
+		if err := os.WriteFile(path, bad, 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if ix, err := Open(dir); err == nil {
+			ix.Close()
+			t.Errorf("índice de %d bytes aberto sem erro", len(bad))
+		}
+	}
+
+	w, err := Create(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w.Abort()
+	if entries, _ := os.ReadDir(dir); len(entries) != 1 {
+		t.Errorf("Abort deixou arquivos: %v", entries)
+	}
+}
@@ -0,0 +1,364 @@
+/*
+  mirror.go - cópia local do packages-meta para o big-aur-packages
+    Chili GNU/Linux - https://github.com/vcatafesta/chili/go
+    Chili GNU/Linux - https://chililinux.com
+    Chili GNU/Linux - https://chilios.com.br
+
+  Created: 2026/10/17
+  Altered: 2026/10/17
+
+  Copyright (c) 2024-2026, Vilmar Catafesta <vcatafesta@gmail.com>
+  All rights reserved.
+
+  Redistribution and use in source and binary forms, with or without
+  modification, are permitted provided that the following conditions
+  are met:
+  1. Redistributions of source code must retain the above copyright
+    notice, this list of conditions and the following disclaimer.
+  2. Redistributions in binary form must reproduce the above copyright
+    notice, this list of conditions and the following disclaimer in the
+    documentation and/or other materials provided with the distribution.
+
+  THIS SOFTWARE IS PROVIDED BY THE AUTHOR ``AS IS'' AND ANY EXPRESS OR
+  IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED WARRANTIES
+  OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE DISCLAIMED.
+  IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR ANY DIRECT, INDIRECT,
+  INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING, BUT
+  NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE,
+  DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY
+  THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT
+  (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF
+  THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+*/
+
+// Package mirror mantém uma cópia local do packages-meta-v1.json.gz, para
+// que o big-aur-packages pesquise sem rede.
+//
+// O Sync usa If-None-Match (ETag) e If-Modified-Since, e um arquivo que não
+// mudou nunca é baixado de novo. O arquivo e o estado (ETag, Last-Modified,
+// hora do sync) são gravados de forma atômica (arquivo temporário + rename):
+// uma busca simultânea ao sync sempre lê a cópia antiga ou a nova inteira.
+//
+// A fonte pode ser uma URL http(s), uma URL file:// ou um caminho local. Se
+// ela não terminar em .json ou .json.gz, é uma lista de mirrors: uma URL por
+// linha (aceita o formato "Server = <url>" do pacman.d/mirrorlist, com
+// comentários '#'), tentadas em ordem até uma funcionar.
+package mirror
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+const (
+	// FileName é o nome da cópia local e o arquivo procurado nos mirrors
+	// da lista que são só o endereço base
+	FileName  = "packages-meta-v1.json.gz"
+	stateName = "packages-meta.state"
+)
+
+// Mirror representa o diretório da cópia local.
+type Mirror struct {
+	Dir    string
+	Client *http.Client
+
+	// Validate, se definido, confere o arquivo baixado antes de ele
+	// substituir a cópia local
+	Validate func(path string) error
+}
+
+// State é o que se sabe da cópia local, gravado em "<dir>/packages-meta.state".
+type State struct {
+	Source       string    `json:"source"` // fonte pedida (arquivo ou lista de mirrors)
+	URL          string    `json:"url"`    // arquivo de onde veio a cópia
+	ETag         string    `json:"etag,omitempty"`
+	LastModified string    `json:"last_modified,omitempty"`
+	Size         int64     `json:"size"`
+	Synced       time.Time `json:"synced"` // último sync com sucesso, mesmo sem mudanças
+}
+
+// Result descreve um Sync bem-sucedido.
+type Result struct {
+	URL     string // arquivo usado
+	Updated bool   // falso quando a fonte respondeu que nada mudou
+	Size    int64  // tamanho da cópia local
+}
+
+// New retorna o Mirror de dir com um cliente HTTP sem tempo máximo total
+// (o arquivo tem dezenas de MB), mas com tempo máximo para a resposta.
+func New(dir string) *Mirror {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.ResponseHeaderTimeout = 30 * time.Second
+	return &Mirror{Dir: dir, Client: &http.Client{Transport: transport}}
+}
+
+// Path é o caminho da cópia local.
+func (m *Mirror) Path() string {
+	return filepath.Join(m.Dir, FileName)
+}
+
+// Open abre a cópia local; o erro satisfaz errors.Is(err, fs.ErrNotExist)
+// se ainda não houve sync.
+func (m *Mirror) Open() (*os.File, error) {
+	return os.Open(m.Path())
+}
+
+// State lê o estado da cópia local.
+func (m *Mirror) State() (State, error) {
+	var st State
+	data, err := os.ReadFile(filepath.Join(m.Dir, stateName))
+	if err != nil {
+		return st, err
+	}
+	if err := json.Unmarshal(data, &st); err != nil {
+		return st, fmt.Errorf("%s: %w", stateName, err)
+	}
+	if _, err := os.Stat(m.Path()); err != nil {
+		return st, err
+	}
+	return st, nil
+}
+
+// Sync atualiza a cópia local a partir de source, tentando cada mirror da
+// lista até um responder.
+func (m *Mirror) Sync(source string) (Result, error) {
+	urls, err := m.URLs(source)
+	if err != nil {
+		return Result{}, err
+	}
+	if err := os.MkdirAll(m.Dir, 0o755); err != nil {
+		return Result{}, err
+	}
+
+	old, _ := m.State()
+	var errs []error
+	for _, u := range urls {
+		st, updated, err := m.fetch(u, old)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", u, err))
+			continue
+		}
+		st.Source, st.URL, st.Synced = source, u, time.Now()
+		if err := m.saveState(st); err != nil {
+			return Result{}, err
+		}
+		return Result{URL: u, Updated: updated, Size: st.Size}, nil
+	}
+	return Result{}, errors.Join(errs...)
+}
+
+// URLs retorna os arquivos de source: ele mesmo, ou os itens da lista de
+// mirrors.
+func (m *Mirror) URLs(source string) ([]string, error) {
+	if isDataFile(source) {
+		return []string{source}, nil
+	}
+	body, err := m.Get(source)
+	if err != nil {
+		return nil, err
+	}
+	defer body.Close()
+	urls, err := ParseMirrorList(body)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", source, err)
+	}
+	if len(urls) == 0 {
+		return nil, fmt.Errorf("%s: lista de mirrors vazia", source)
+	}
+	return urls, nil
+}
+
+// Get abre u para leitura, seja http(s), file:// ou caminho local.
+func (m *Mirror) Get(u string) (io.ReadCloser, error) {
+	if path, ok := localPath(u); ok {
+		return os.Open(path)
+	}
+	resp, err := m.Client.Get(u)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, fmt.Errorf("HTTP %s", resp.Status)
+	}
+	return resp.Body, nil
+}
+
+// ParseMirrorList lê uma URL por linha, ignorando linhas vazias e
+// comentários; "Server = <url>" também é aceito. Mirrors que não apontam
+// para um .json ou .json.gz recebem "/packages-meta-v1.json.gz".
+func ParseMirrorList(r io.Reader) ([]string, error) {
+	var urls []string
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line, _, _ := strings.Cut(scanner.Text(), "#")
+		line = strings.TrimSpace(line)
+		if key, value, ok := strings.Cut(line, "="); ok && strings.EqualFold(strings.TrimSpace(key), "server") {
+			line = strings.TrimSpace(value)
+		}
+		if line == "" {
+			continue
+		}
+		if !isDataFile(line) {
+			line = strings.TrimRight(line, "/") + "/" + FileName
+		}
+		urls = append(urls, line)
+	}
+	return urls, scanner.Err()
+}
+
+// fetch baixa u para a cópia local se ele mudou desde old.
+func (m *Mirror) fetch(u string, old State) (State, bool, error) {
+	if path, ok := localPath(u); ok {
+		return m.fetchFile(path, u, old)
+	}
+
+	req, err := http.NewRequest(http.MethodGet, u, nil)
+	if err != nil {
+		return State{}, false, err
+	}
+	// O ETag e o Last-Modified só valem para o servidor que os gerou: outro
+	// mirror pode ter uma cópia mais antiga com data de modificação posterior
+	if m.hasCopy() && old.URL == u {
+		if old.ETag != "" {
+			req.Header.Set("If-None-Match", old.ETag)
+		}
+		if old.LastModified != "" {
+			req.Header.Set("If-Modified-Since", old.LastModified)
+		}
+	}
+	resp, err := m.Client.Do(req)
+	if err != nil {
+		return State{}, false, err
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusNotModified:
+		old.URL = u
+		if etag := resp.Header.Get("ETag"); etag != "" {
+			old.ETag = etag
+		}
+		return old, false, nil
+	case http.StatusOK:
+	default:
+		return State{}, false, fmt.Errorf("HTTP %s", resp.Status)
+	}
+
+	size, err := m.store(resp.Body)
+	if err != nil {
+		return State{}, false, err
+	}
+	return State{
+		ETag:         resp.Header.Get("ETag"),
+		LastModified: resp.Header.Get("Last-Modified"),
+		Size:         size,
+	}, true, nil
+}
+
+// fetchFile copia um arquivo local, usando a data de modificação e o
+// tamanho no lugar do Last-Modified.
+func (m *Mirror) fetchFile(path, u string, old State) (State, bool, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return State{}, false, err
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return State{}, false, err
+	}
+	lastModified := info.ModTime().UTC().Format(http.TimeFormat)
+	if m.hasCopy() && old.URL == u && old.LastModified == lastModified && old.Size == info.Size() {
+		return old, false, nil
+	}
+	size, err := m.store(f)
+	if err != nil {
+		return State{}, false, err
+	}
+	return State{LastModified: lastModified, Size: size}, true, nil
+}
+
+// store grava r na cópia local de forma atômica.
+func (m *Mirror) store(r io.Reader) (int64, error) {
+	tmp, err := os.CreateTemp(m.Dir, ".sync-*")
+	if err != nil {
+		return 0, err
+	}
+	defer os.Remove(tmp.Name())
+
+	size, err := io.Copy(tmp, r)
+	if err == nil {
+		err = tmp.Close()
+	} else {
+		tmp.Close()
+	}
+	if err == nil && m.Validate != nil {
+		err = m.Validate(tmp.Name())
+	}
+	if err == nil {
+		err = os.Chmod(tmp.Name(), 0o644)
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), m.Path())
+	}
+	return size, err
+}
+
+func (m *Mirror) saveState(st State) error {
+	data, err := json.MarshalIndent(st, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(m.Dir, ".state-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	_, err = tmp.Write(append(data, '\n'))
+	if cerr := tmp.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil {
+		err = os.Chmod(tmp.Name(), 0o644)
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), filepath.Join(m.Dir, stateName))
+	}
+	return err
+}
+
+func (m *Mirror) hasCopy() bool {
+	_, err := os.Stat(m.Path())
+	return err == nil
+}
+
+// isDataFile diz se u aponta para o próprio packages-meta (.json ou
+// .json.gz) e não para uma lista de mirrors.
+func isDataFile(u string) bool {
+	path := u
+	if parsed, err := url.Parse(u); err == nil && parsed.Path != "" {
+		path = parsed.Path
+	}
+	return strings.HasSuffix(path, ".json") || strings.HasSuffix(path, ".json.gz")
+}
+
+// localPath converte file:///caminho e caminhos sem esquema.
+func localPath(u string) (string, bool) {
+	if path, ok := strings.CutPrefix(u, "file://"); ok {
+		return path, true
+	}
+	if !strings.Contains(u, "://") {
+		return u, true
+	}
+	return "", false
+}
@@ -0,0 +1,175 @@
+package mirror
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+const lastModified = "Sat, 17 Oct 2026 12:00:00 GMT"
+
+// fakeServer serve data com ETag e Last-Modified e conta os downloads
+// completos (respostas 200)
+func fakeServer(t *testing.T, data *atomic.Value, downloads *atomic.Int32) *httptest.Server {
+	t.Helper()
+	mux := http.NewServeMux()
+	mux.HandleFunc("/packages-meta-v1.json.gz", func(w http.ResponseWriter, r *http.Request) {
+		body := data.Load().(string)
+		etag := `"` + strings.ToUpper(body[:4]) + `"`
+		w.Header().Set("ETag", etag)
+		w.Header().Set("Last-Modified", lastModified)
+		if r.Header.Get("If-None-Match") == etag {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		downloads.Add(1)
+		w.Write([]byte(body))
+	})
+	mux.HandleFunc("/broken/", http.NotFound)
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestSyncConditional(t *testing.T) {
+	var data atomic.Value
+	var downloads atomic.Int32
+	data.Store("v1-data")
+	srv := fakeServer(t, &data, &downloads)
+	m := New(t.TempDir())
+	source := srv.URL + "/packages-meta-v1.json.gz"
+
+	for i, want := range []bool{true, false, false} {
+		result, err := m.Sync(source)
+		if err != nil || result.Updated != want {
+			t.Fatalf("sync %d: updated=%v erro %v, esperado %v", i, result.Updated, err, want)
+		}
+	}
+	if n := downloads.Load(); n != 1 {
+		t.Errorf("%d downloads, esperado 1", n)
+	}
+
+	data.Store("v2-data")
+	if result, err := m.Sync(source); err != nil || !result.Updated {
+		t.Fatalf("sync após mudança: %+v, %v", result, err)
+	}
+	got, _ := os.ReadFile(m.Path())
+	st, err := m.State()
+	if string(got) != "v2-data" || err != nil || st.ETag != `"V2-D"` || st.LastModified != lastModified || st.Source != source {
+		t.Errorf("cópia %q, estado %+v, erro %v", got, st, err)
+	}
+}
+
+// TestSyncOtherMirror troca de servidor: o novo não recebe os cabeçalhos
+// condicionais gravados a partir do anterior
+func TestSyncOtherMirror(t *testing.T) {
+	var data atomic.Value
+	var downloads atomic.Int32
+	data.Store("v1-data")
+	first := fakeServer(t, &data, &downloads)
+
+	var conditional atomic.Bool
+	second := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("If-None-Match") != "" || r.Header.Get("If-Modified-Since") != "" {
+			conditional.Store(true)
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Write([]byte("v2-data"))
+	}))
+	t.Cleanup(second.Close)
+
+	m := New(t.TempDir())
+	if _, err := m.Sync(first.URL + "/" + FileName); err != nil {
+		t.Fatal(err)
+	}
+	result, err := m.Sync(second.URL + "/" + FileName)
+	if err != nil || !result.Updated {
+		t.Fatalf("sync no segundo mirror: %+v, %v", result, err)
+	}
+	if conditional.Load() {
+		t.Error("o segundo mirror recebeu If-None-Match ou If-Modified-Since do primeiro")
+	}
+	if got, _ := os.ReadFile(m.Path()); string(got) != "v2-data" {
+		t.Errorf("cópia %q, esperado v2-data", got)
+	}
+}
+
+func TestSyncMirrorList(t *testing.T) {
+	var data atomic.Value
+	var downloads atomic.Int32
+	data.Store("v1-data")
+	srv := fakeServer(t, &data, &downloads)
+
+	dir := t.TempDir()
+	list := filepath.Join(dir, "mirrorlist")
+	content := "# mirrors do packages-meta\n" +
+		"Server = " + srv.URL + "/broken/\n" +
+		"\n" +
+		srv.URL + " # só o endereço base\n"
+	if err := os.WriteFile(list, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	m := New(filepath.Join(dir, "cache"))
+	result, err := m.Sync("file://" + list)
+	if err != nil || result.URL != srv.URL+"/"+FileName {
+		t.Fatalf("sync: %+v, %v", result, err)
+	}
+
+	if _, err := m.Sync(filepath.Join(dir, "nao-existe")); err == nil {
+		t.Error("lista inexistente não retornou erro")
+	}
+}
+
+func TestSyncFile(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "packages-meta-v1.json.gz")
+	if err := os.WriteFile(file, []byte("v1-data"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	m := New(filepath.Join(dir, "cache"))
+	for i, want := range []bool{true, false} {
+		if result, err := m.Sync("file://" + file); err != nil || result.Updated != want {
+			t.Fatalf("sync %d: %+v, %v", i, result, err)
+		}
+	}
+
+	// Validate recusa o arquivo novo e a cópia antiga continua
+	if err := os.WriteFile(file, []byte("corrompido"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	os.Chtimes(file, time.Now(), time.Now().Add(time.Hour))
+	m.Validate = func(path string) error {
+		data, _ := os.ReadFile(path)
+		if string(data) == "corrompido" {
+			return errors.New("inválido")
+		}
+		return nil
+	}
+	if _, err := m.Sync(file); err == nil {
+		t.Error("Validate não impediu o sync")
+	}
+	if got, _ := os.ReadFile(m.Path()); string(got) != "v1-data" {
+		t.Errorf("cópia local %q após sync recusado", got)
+	}
+	if entries, _ := os.ReadDir(m.Dir); len(entries) != 2 {
+		t.Errorf("sobraram arquivos temporários: %v", entries)
+	}
+}
+
+func TestParseMirrorList(t *testing.T) {
+	in := "Server = https://a.example/aur/\nhttps://b.example/meta.json\n#https://c.example\n"
+	got, err := ParseMirrorList(strings.NewReader(in))
+	want := "https://a.example/aur/packages-meta-v1.json.gz https://b.example/meta.json"
+	if err != nil || strings.Join(got, " ") != want {
+		t.Errorf("ParseMirrorList = %q, %v", got, err)
+	}
+}
@@ -0,0 +1,549 @@
+/*
+  query.go - linguagem de consulta do big-aur-packages
+    Chili GNU/Linux - https://github.com/vcatafesta/chili/go
+    Chili GNU/Linux - https://chililinux.com
+    Chili GNU/Linux - https://chilios.com.br
+
+  Created: 2026/10/17
+  Altered: 2026/10/17
+
+  Copyright (c) 2024-2026, Vilmar Catafesta <vcatafesta@gmail.com>
+  All rights reserved.
+
+  Redistribution and use in source and binary forms, with or without
+  modification, are permitted provided that the following conditions
+  are met:
+  1. Redistributions of source code must retain the above copyright
+    notice, this list of conditions and the following disclaimer.
+  2. Redistributions in binary form must reproduce the above copyright
+    notice, this list of conditions and the following disclaimer in the
+    documentation and/or other materials provided with the distribution.
+
+  THIS SOFTWARE IS PROVIDED BY THE AUTHOR ``AS IS'' AND ANY EXPRESS OR
+  IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED WARRANTIES
+  OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE DISCLAIMED.
+  IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR ANY DIRECT, INDIRECT,
+  INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING, BUT
+  NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE,
+  DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY
+  THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT
+  (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF
+  THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+*/
+
+// Package query é a linguagem de consulta do --query do big-aur-packages:
+//
+//	name:yay OR (maintainer:=bob AND votes:>100 AND NOT outofdate:true)
+//	"web browser" popularity:>=0.5 votes:10..500
+//
+// Uma palavra solta ou uma frase entre aspas é um termo sem campo. Um termo
+// com campo é campo:valor, e o valor pode vir entre aspas (name:"foo bar").
+// Campos de texto aceitam campo:valor (contém) e campo:=valor (igual), sem
+// diferenciar maiúsculas; campos de prefixo e de palavra aceitam só
+// campo:valor, e o valor de um campo de palavra precisa ser uma palavra só
+// (letras e dígitos); campos numéricos aceitam =, >, >=, <, <= e intervalos
+// a..b; campos booleanos aceitam true e false; campos regex recebem uma
+// expressão regular, que precisa de aspas se tiver espaços ou parênteses.
+//
+// AND, OR e NOT (em maiúsculas) combinam os termos, nessa precedência:
+// NOT, AND, OR. Termos lado a lado sem operador são ligados por AND.
+//
+// Parse valida os campos com um Schema e devolve a árvore; quem chama
+// decide o que cada campo significa ao avaliar os termos com Eval.
+package query
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Kind é o tipo de valor de um campo.
+type Kind int
+
+const (
+	Text   Kind = iota // contém ou igual, sem diferenciar maiúsculas
+	Number             // comparações e intervalos
+	Bool               // true ou false
+	Regexp             // expressão regular
+	Prefix             // começo do texto, sem diferenciar maiúsculas
+	Word               // uma palavra (letras e dígitos), sem diferenciar maiúsculas
+)
+
+// Schema são os campos aceitos, em minúsculas. O campo "" é o dos termos
+// sem campo e precisa estar presente.
+type Schema map[string]Kind
+
+// Op é a comparação de um termo.
+type Op int
+
+const (
+	Contains Op = iota
+	Equal
+	Less
+	LessEqual
+	Greater
+	GreaterEqual
+	Range
+)
+
+var opNames = map[Op]string{
+	Contains:     ":",
+	Equal:        ":=",
+	Less:         ":<",
+	LessEqual:    ":<=",
+	Greater:      ":>",
+	GreaterEqual: ":>=",
+}
+
+// Node é um nó da árvore da consulta.
+type Node interface {
+	// Eval avalia o nó, chamando match para cada termo necessário.
+	Eval(match func(*Term) bool) bool
+	String() string
+}
+
+// And é verdadeiro se L e R forem.
+type And struct{ L, R Node }
+
+// Or é verdadeiro se L ou R for.
+type Or struct{ L, R Node }
+
+// Not inverte X.
+type Not struct{ X Node }
+
+// Term é um termo da consulta.
+type Term struct {
+	Field string // em minúsculas; "" para termos sem campo
+	Kind  Kind
+	Op    Op
+	Text  string  // valor de Text, Prefix e Word, em minúsculas
+	Num   float64 // valor de Number; início do intervalo em Range
+	Max   float64 // fim do intervalo em Range
+	Bool  bool
+	Re    *regexp.Regexp
+	Col   int // coluna do termo na consulta, a partir de 1
+}
+
+func (n *And) Eval(match func(*Term) bool) bool  { return n.L.Eval(match) && n.R.Eval(match) }
+func (n *Or) Eval(match func(*Term) bool) bool   { return n.L.Eval(match) || n.R.Eval(match) }
+func (n *Not) Eval(match func(*Term) bool) bool  { return !n.X.Eval(match) }
+func (t *Term) Eval(match func(*Term) bool) bool { return match(t) }
+
+func (n *And) String() string { return "(" + n.L.String() + " AND " + n.R.String() + ")" }
+func (n *Or) String() string  { return "(" + n.L.String() + " OR " + n.R.String() + ")" }
+func (n *Not) String() string { return "NOT " + n.X.String() }
+
+func (t *Term) String() string {
+	var value string
+	switch t.Kind {
+	case Text, Prefix, Word:
+		value = strconv.Quote(t.Text)
+	case Number:
+		value = strconv.FormatFloat(t.Num, 'g', -1, 64)
+		if t.Op == Range {
+			value += ".." + strconv.FormatFloat(t.Max, 'g', -1, 64)
+		}
+	case Bool:
+		value = strconv.FormatBool(t.Bool)
+	case Regexp:
+		value = strconv.Quote(t.Re.String())
+	}
+	if t.Field == "" {
+		return value
+	}
+	if t.Op == Range || t.Kind == Bool {
+		return t.Field + ":" + value
+	}
+	return t.Field + opNames[t.Op] + value
+}
+
+// MatchText compara s com um termo Text.
+func (t *Term) MatchText(s string) bool {
+	s = strings.ToLower(s)
+	if t.Op == Equal {
+		return s == t.Text
+	}
+	return strings.Contains(s, t.Text)
+}
+
+// Compare compara x com um termo Number.
+func (t *Term) Compare(x float64) bool {
+	switch t.Op {
+	case Less:
+		return x < t.Num
+	case LessEqual:
+		return x <= t.Num
+	case Greater:
+		return x > t.Num
+	case GreaterEqual:
+		return x >= t.Num
+	case Range:
+		return x >= t.Num && x <= t.Max
+	}
+	return x == t.Num
+}
+
+// SyntaxError é um erro na consulta.
+type SyntaxError struct {
+	Col int // coluna do erro, em caracteres, a partir de 1
+	Msg string
+}
+
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf("coluna %d: %s", e.Col, e.Msg)
+}
+
+// Parse lê a consulta s.
+func Parse(s string, schema Schema) (Node, error) {
+	p := &parser{src: s, schema: schema}
+	if err := p.lex(); err != nil {
+		return nil, err
+	}
+	if p.peek().kind == tokEOF {
+		return nil, p.errorf(p.peek(), "consulta vazia")
+	}
+	n, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if tok := p.peek(); tok.kind != tokEOF {
+		if tok.kind == tokRParen {
+			return nil, p.errorf(tok, "')' sem '(' correspondente")
+		}
+		return nil, p.errorf(tok, "esperado AND, OR ou fim da consulta")
+	}
+	return n, nil
+}
+
+type tokKind int
+
+const (
+	tokEOF tokKind = iota
+	tokLParen
+	tokRParen
+	tokAnd
+	tokOr
+	tokNot
+	tokTerm
+)
+
+type token struct {
+	kind tokKind
+	pos  int // posição em bytes
+	term *Term
+}
+
+type parser struct {
+	src    string
+	schema Schema
+	toks   []token
+	next   int
+}
+
+func (p *parser) col(pos int) int {
+	return utf8.RuneCountInString(p.src[:pos]) + 1
+}
+
+func (p *parser) errorf(tok token, format string, args ...any) error {
+	return &SyntaxError{Col: p.col(tok.pos), Msg: fmt.Sprintf(format, args...)}
+}
+
+func (p *parser) errorAt(pos int, format string, args ...any) error {
+	return &SyntaxError{Col: p.col(pos), Msg: fmt.Sprintf(format, args...)}
+}
+
+func (p *parser) peek() token {
+	return p.toks[p.next]
+}
+
+func (p *parser) advance() token {
+	tok := p.toks[p.next]
+	p.next++
+	return tok
+}
+
+// parseOr: and (OR and)*
+func (p *parser) parseOr() (Node, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.peek().kind == tokOr {
+		p.advance()
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = &Or{left, right}
+	}
+	return left, nil
+}
+
+// parseAnd: unary ([AND] unary)*
+func (p *parser) parseAnd() (Node, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		switch p.peek().kind {
+		case tokAnd:
+			p.advance()
+		case tokTerm, tokLParen, tokNot:
+		default:
+			return left, nil
+		}
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = &And{left, right}
+	}
+}
+
+// parseUnary: NOT unary | '(' or ')' | termo
+func (p *parser) parseUnary() (Node, error) {
+	tok := p.advance()
+	switch tok.kind {
+	case tokNot:
+		x, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return &Not{x}, nil
+	case tokLParen:
+		n, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if p.peek().kind != tokRParen {
+			if p.peek().kind == tokEOF {
+				return nil, p.errorf(tok, "'(' sem ')' correspondente")
+			}
+			return nil, p.errorf(p.peek(), "esperado ')'")
+		}
+		p.advance()
+		return n, nil
+	case tokTerm:
+		return tok.term, nil
+	case tokEOF:
+		return nil, p.errorf(tok, "esperado um termo no fim da consulta")
+	}
+	return nil, p.errorf(tok, "esperado um termo, encontrado '%s'", p.text(tok))
+}
+
+func (p *parser) text(tok token) string {
+	switch tok.kind {
+	case tokLParen:
+		return "("
+	case tokRParen:
+		return ")"
+	case tokAnd:
+		return "AND"
+	case tokOr:
+		return "OR"
+	case tokNot:
+		return "NOT"
+	}
+	return ""
+}
+
+// lex separa a consulta em tokens; o último é sempre tokEOF
+func (p *parser) lex() error {
+	s := p.src
+	for i := 0; i < len(s); {
+		r, size := utf8.DecodeRuneInString(s[i:])
+		switch {
+		case unicode.IsSpace(r):
+			i += size
+		case r == '(':
+			p.toks = append(p.toks, token{kind: tokLParen, pos: i})
+			i++
+		case r == ')':
+			p.toks = append(p.toks, token{kind: tokRParen, pos: i})
+			i++
+		case r == '"':
+			phrase, end, err := p.quoted(i)
+			if err != nil {
+				return err
+			}
+			term, err := p.term(i, "", i, phrase, true)
+			if err != nil {
+				return err
+			}
+			p.toks = append(p.toks, token{kind: tokTerm, pos: i, term: term})
+			i = end
+		default:
+			start := i
+			for i < len(s) && !strings.ContainsRune(" \t\n\r()\"", rune(s[i])) {
+				i++
+			}
+			word := s[start:i]
+			switch word {
+			case "AND":
+				p.toks = append(p.toks, token{kind: tokAnd, pos: start})
+				continue
+			case "OR":
+				p.toks = append(p.toks, token{kind: tokOr, pos: start})
+				continue
+			case "NOT":
+				p.toks = append(p.toks, token{kind: tokNot, pos: start})
+				continue
+			}
+
+			field, value, hasField := strings.Cut(word, ":")
+			valuePos, quoted := start+len(field)+1, false
+			if !hasField || !isIdent(field) {
+				field, value, valuePos = "", word, start
+			} else if i < len(s) && s[i] == '"' && (value == "" || strings.Trim(value, "=<>") == "") {
+				// name:"foo bar" e votes:>"10"
+				phrase, end, err := p.quoted(i)
+				if err != nil {
+					return err
+				}
+				value, quoted = value+phrase, true
+				i = end
+			}
+			term, err := p.term(start, strings.ToLower(field), valuePos, value, quoted)
+			if err != nil {
+				return err
+			}
+			p.toks = append(p.toks, token{kind: tokTerm, pos: start, term: term})
+		}
+	}
+	p.toks = append(p.toks, token{kind: tokEOF, pos: len(s)})
+	return nil
+}
+
+// quoted lê a frase entre aspas que começa em s[start]; \" e \\ são
+// escapes
+func (p *parser) quoted(start int) (string, int, error) {
+	var sb strings.Builder
+	s := p.src
+	for i := start + 1; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			if i+1 < len(s) && (s[i+1] == '"' || s[i+1] == '\\') {
+				i++
+			}
+		case '"':
+			return sb.String(), i + 1, nil
+		}
+		sb.WriteByte(s[i])
+	}
+	return "", 0, p.errorAt(start, "aspas sem fechamento")
+}
+
+// term monta o termo field:value de acordo com o tipo do campo
+func (p *parser) term(pos int, field string, valuePos int, value string, quoted bool) (*Term, error) {
+	kind, ok := p.schema[field]
+	if !ok {
+		return nil, p.errorAt(pos, "campo desconhecido '%s' (use aspas para buscar o texto)", field)
+	}
+	t := &Term{Field: field, Kind: kind, Col: p.col(pos)}
+	if value == "" && !quoted {
+		return nil, p.errorAt(valuePos, "falta o valor de '%s'", field)
+	}
+
+	switch kind {
+	case Text:
+		if field != "" {
+			if rest, ok := strings.CutPrefix(value, "="); ok {
+				t.Op, value = Equal, rest
+			}
+		}
+		t.Text = strings.ToLower(value)
+
+	case Prefix, Word:
+		if strings.HasPrefix(value, "=") {
+			return nil, p.errorAt(valuePos, "'%s' não aceita ':='", field)
+		}
+		t.Text = strings.ToLower(value)
+		if kind == Word && !isWord(t.Text) {
+			return nil, p.errorAt(valuePos, "'%s' aceita uma palavra só, de letras e dígitos", field)
+		}
+
+	case Number:
+		t.Op = Equal
+		for _, op := range []Op{GreaterEqual, LessEqual, Greater, Less, Equal} {
+			if rest, ok := strings.CutPrefix(value, opNames[op][1:]); ok {
+				t.Op, value = op, rest
+				valuePos += len(opNames[op]) - 1
+				break
+			}
+		}
+		if lo, hi, isRange := strings.Cut(value, ".."); isRange && t.Op == Equal {
+			min, err := p.number(valuePos, lo)
+			if err != nil {
+				return nil, err
+			}
+			max, err := p.number(valuePos+len(lo)+2, hi)
+			if err != nil {
+				return nil, err
+			}
+			if min > max {
+				return nil, p.errorAt(valuePos, "intervalo invertido %s", value)
+			}
+			t.Op, t.Num, t.Max = Range, min, max
+			break
+		}
+		n, err := p.number(valuePos, value)
+		if err != nil {
+			return nil, err
+		}
+		t.Num = n
+
+	case Bool:
+		b, err := strconv.ParseBool(strings.ToLower(value))
+		if err != nil {
+			return nil, p.errorAt(valuePos, "'%s' requer true ou false", field)
+		}
+		t.Bool = b
+
+	case Regexp:
+		re, err := regexp.Compile(value)
+		if err != nil {
+			return nil, p.errorAt(valuePos, "regex inválida: %v", err)
+		}
+		t.Re = re
+	}
+	return t, nil
+}
+
+func (p *parser) number(pos int, value string) (float64, error) {
+	n, err := strconv.ParseFloat(value, 64)
+	if err != nil || value == "" {
+		return 0, p.errorAt(pos, "número inválido '%s'", value)
+	}
+	return n, nil
+}
+
+// isWord diz se s é uma palavra só, como as que o índice separa: letras e
+// dígitos
+func isWord(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, r := range s {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			return false
+		}
+	}
+	return true
+}
+
+func isIdent(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, r := range s {
+		if !unicode.IsLetter(r) && r != '_' {
+			return false
+		}
+	}
+	return true
+}
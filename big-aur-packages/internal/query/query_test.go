@@ -0,0 +1,119 @@
+package query
+
+import (
+	"errors"
+	"testing"
+)
+
+var testSchema = Schema{
+	"":          Text,
+	"name":      Text,
+	"votes":     Number,
+	"outofdate": Bool,
+	"regex":     Regexp,
+	"prefix":    Prefix,
+	"word":      Word,
+}
+
+func TestParse(t *testing.T) {
+	cases := []struct{ in, want string }{
+		{`yay`, `"yay"`},
+		{`Yay paru`, `("yay" AND "paru")`},
+		{`yay OR paru AND NOT outofdate:true`, `("yay" OR ("paru" AND NOT outofdate:true))`},
+		{`(yay OR paru) AND votes:>100`, `(("yay" OR "paru") AND votes:>100)`},
+		{`name:="google chrome" votes:>=0.5`, `(name:="google chrome" AND votes:>=0.5)`},
+		{`name:"web \"browser\"" votes:10..500`, `(name:"web \"browser\"" AND votes:10..500)`},
+		{`"aur helper" NOT NOT votes:<3`, `("aur helper" AND NOT NOT votes:<3)`},
+		{`regex:"^(yay|paru)$" c++:x`, `(regex:"^(yay|paru)$" AND "c++:x")`},
+		{`and or not`, `(("and" AND "or") AND "not")`},
+		{`prefix:Qt5- word:Qt5`, `(prefix:"qt5-" AND word:"qt5")`},
+		{`prefix:"lib foo" word:ação`, `(prefix:"lib foo" AND word:"ação")`},
+	}
+	for _, c := range cases {
+		n, err := Parse(c.in, testSchema)
+		if err != nil {
+			t.Errorf("Parse(%q): %v", c.in, err)
+			continue
+		}
+		if got := n.String(); got != c.want {
+			t.Errorf("Parse(%q) = %s, esperado %s", c.in, got, c.want)
+		}
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	cases := []struct {
+		in  string
+		col int
+	}{
+		{``, 1},
+		{`   `, 4},
+		{`yay AND`, 8},
+		{`(yay OR paru`, 1},
+		{`yay)`, 4},
+		{`yay OR OR paru`, 8},
+		{`votes:>abc`, 8},
+		{`votes:10..x`, 11},
+		{`votes:9..1`, 7},
+		{`outofdate:talvez`, 11},
+		{`ação foo:bar`, 6},
+		{`name:`, 6},
+		{`name:"sem fim`, 6},
+		{`regex:"(" yay`, 7},
+		{`(yay paru))`, 11},
+		{`prefix:=yay`, 8},
+		{`yay word:=yay`, 10},
+		{`word:qt5-base`, 6},
+		{`word:"web browser"`, 6},
+		{`word:""`, 6},
+	}
+	for _, c := range cases {
+		_, err := Parse(c.in, testSchema)
+		var syntaxErr *SyntaxError
+		if !errors.As(err, &syntaxErr) {
+			t.Errorf("Parse(%q): erro %v, esperado SyntaxError", c.in, err)
+			continue
+		}
+		if syntaxErr.Col != c.col {
+			t.Errorf("Parse(%q): %v, esperado coluna %d", c.in, err, c.col)
+		}
+	}
+}
+
+func TestEval(t *testing.T) {
+	n, err := Parse(`(name:yay OR name:=PARU) votes:1..1000 NOT outofdate:true`, testSchema)
+	if err != nil {
+		t.Fatal(err)
+	}
+	type pkg struct {
+		name      string
+		votes     float64
+		outOfDate bool
+	}
+	cases := []struct {
+		p    pkg
+		want bool
+	}{
+		{pkg{"yay-bin", 10, false}, true},
+		{pkg{"Paru", 1000, false}, true},
+		{pkg{"paru-bin", 10, false}, false},
+		{pkg{"yay", 1001, false}, false},
+		{pkg{"yay", 10, true}, false},
+	}
+	for _, c := range cases {
+		got := n.Eval(func(t *Term) bool {
+			switch t.Field {
+			case "name":
+				return t.MatchText(c.p.name)
+			case "votes":
+				return t.Compare(c.p.votes)
+			case "outofdate":
+				return c.p.outOfDate == t.Bool
+			}
+			return false
+		})
+		if got != c.want {
+			t.Errorf("%+v = %v, esperado %v", c.p, got, c.want)
+		}
+	}
+}
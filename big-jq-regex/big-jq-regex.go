@@ -35,6 +35,7 @@
 package main
 
 import (
+	"colors"
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
@@ -51,31 +52,12 @@ const (
 	_PKGDESC_   = "Utilitario like jq para uso com AUR json https://aur.archlinux.org/packages-meta-v1.json.gz"
 	_VERSION_   = "0.13.0-20240813"
 	_COPYRIGHT_ = "Copyright (C) 2024 Vilmar Catafesta, <vcatafesta@gmail.com>"
-
-	// Constantes para cores ANSI
-	Reset        = "\x1b[0m"
-	Black        = "\x1b[30m"       // `tput setaf 0`
-	Red          = "\x1b[31m"       // `tput setaf 196`
-	Green        = "\x1b[32m"       // `tput setaf 2`
-	Yellow       = "\x1b[33m"       // `tput setaf 3`
-	Blue         = "\x1b[34m"       // `tput setaf 4`
-	Pink         = "\x1b[35m"       // `tput setaf 5`
-	Magenta      = "\x1b[35m"       // `tput setaf 5` (Pink and Magenta are the same here)
-	Cyan         = "\x1b[36m"       // `tput setaf 6`
-	White        = "\x1b[37m"       // `tput setaf 7`
-	Gray         = "\x1b[90m"       // `tput setaf 8`
-	Orange       = "\x1b[38;5;202m" // `tput setaf 202`
-	Purple       = "\x1b[38;5;125m" // `tput setaf 125`
-	Violet       = "\x1b[38;5;61m"  // `tput setaf 61`
-	LightRed     = "\x1b[38;5;9m"   // `tput setaf 9`
-	LightGreen   = "\x1b[38;5;10m"  // `tput setaf 10`
-	LightYellow  = "\x1b[38;5;11m"  // `tput setaf 11`
-	LightBlue    = "\x1b[38;5;12m"  // `tput setaf 12`
-	LightMagenta = "\x1b[38;5;13m"  // `tput setaf 13`
-	LightCyan    = "\x1b[38;5;14m"  // `tput setaf 14`
-	BrightWhite  = "\x1b[97m"       // `tput setaf 15` (White is often used for Bright White)
 )
 
+// Cores ANSI da saída padrão (cout) e da saída de erro (cerr). Ficam vazias
+// quando a saída não é um terminal, com NO_COLOR ou com --color=never.
+var cout, cerr = &colors.Stdout, &colors.Stderr
+
 // Definição da estrutura Package
 type Package struct {
 	ID             int     `json:"ID"`
@@ -102,6 +84,14 @@ var limit int
 var verbose bool
 
 func main() {
+	// Remove o --color de os.Args: o -C lê os campos pela posição
+	colorMode, rest, err := colors.FromArgs(os.Args[1:])
+	if err != nil {
+		log.Fatalf("%sErro: %v%s\n", cerr.Red, err, cerr.Reset)
+	}
+	colors.Setup(colorMode)
+	os.Args = append(os.Args[:1], rest...)
+
 	if len(os.Args) < 2 {
 		printUsageAndExit()
 	}
@@ -160,9 +150,9 @@ func parseArgs() (command string, showJSON, useRegex bool) {
 		case "--verbose":
 			verbose = true
 		case "-V", "--version":
-			fmt.Println(Red + _APP_ + " - " + _PKGDESC_ + Reset)
-			fmt.Println(Cyan + "big-jq-regex - v" + _VERSION_ + Reset)
-			fmt.Println("   " + _COPYRIGHT_ + Reset)
+			fmt.Println(cout.Red + _APP_ + " - " + _PKGDESC_ + cout.Reset)
+			fmt.Println(cout.Cyan + "big-jq-regex - v" + _VERSION_ + cout.Reset)
+			fmt.Println("   " + _COPYRIGHT_ + cout.Reset)
 			fmt.Println("")
 			fmt.Println("   Este programa pode ser redistribuído livremente")
 			fmt.Println("   sob os termos da Licença Pública Geral GNU.")
@@ -195,14 +185,15 @@ func parseArgs() (command string, showJSON, useRegex bool) {
 }
 
 func printUsageAndExit() {
-	fmt.Println(Red + _APP_ + " - " + _PKGDESC_ + Reset)
-	fmt.Println(Cyan + "big-jq-regex - v" + _VERSION_ + Reset)
-	fmt.Println("   " + _COPYRIGHT_ + Reset)
-	fmt.Println(Cyan + "Uso:" + Reset)
+	fmt.Println(cout.Red + _APP_ + " - " + _PKGDESC_ + cout.Reset)
+	fmt.Println(cout.Cyan + "big-jq-regex - v" + _VERSION_ + cout.Reset)
+	fmt.Println("   " + _COPYRIGHT_ + cout.Reset)
+	fmt.Println(cout.Cyan + "Uso:" + cout.Reset)
 	fmt.Println("  big-jq-regex -S|--search -f <arquivo_json> <search> [<search>...] [--json] [--limit] [--verbose]")
 	fmt.Println("  big-jq-regex -S|--search -f <arquivo_json> <regex_pattern> [--json] [--regex] [--limit] [--verbose]")
 	fmt.Println("  big-jq-regex -L|--list   -f <arquivo_json> [--json]")
 	fmt.Println("  big-jq-regex -C|--create -f <arquivo_json> <id> <name> <package_base_id> <package_base> <version> <description> <url> <num_votes> <popularity> <out_of_date> <maintainer> <submitter> <first_submitted> <last_modified> <url_path>")
+	fmt.Println("  Opção --color auto|always|never: cores só em terminal e sem NO_COLOR (padrão), sempre ou nunca")
 	os.Exit(1)
 }
 
@@ -301,7 +292,7 @@ func handleCreate(jsonFile string) {
 			log.Fatalf("Erro ao escrever no arquivo JSON: %v\n", err)
 		}
 		if verbose {
-			log.Printf("%s %sSET: %s'%d'%s no arquivo %s - 200 OK%s\n", _APP_, Green, Yellow, id, Cyan, jsonFile, Reset)
+			log.Printf("%s %sSET: %s'%d'%s no arquivo %s - 200 OK%s\n", _APP_, cerr.Green, cerr.Yellow, id, cerr.Cyan, jsonFile, cerr.Reset)
 		}
 	} else {
 		log.Printf("Nada a ser atualizado no arquivo JSON: %s\n", jsonFile)
@@ -358,7 +349,7 @@ func searchWithRegex(jsonFile string, data map[int]Package, pattern string, show
 				foundAny = true
 				count++
 				if verbose {
-					log.Printf("%s %sGET: %s'%s'%s em %s %s- 200 OK%s\n", _APP_, Green, Yellow, strings.TrimSpace(pkg.Name), Cyan, jsonFile, Green, Reset)
+					log.Printf("%s %sGET: %s'%s'%s em %s %s- 200 OK%s\n", _APP_, cerr.Green, cerr.Yellow, strings.TrimSpace(pkg.Name), cerr.Cyan, jsonFile, cerr.Green, cerr.Reset)
 				}
 				if showJSON {
 					printJSON(pkg)
@@ -391,7 +382,7 @@ func searchWithRegex(jsonFile string, data map[int]Package, pattern string, show
 	if !foundAny {
 		// Se nenhum pacote for encontrado, imprime a mensagem de erro
 		if verbose {
-			log.Printf("%s %sGET: %s'%s'%s em %s %s- 404 NOK%s\n", _APP_, Red, Yellow, pattern, Cyan, jsonFile, Red, Reset)
+			log.Printf("%s %sGET: %s'%s'%s em %s %s- 404 NOK%s\n", _APP_, cerr.Red, cerr.Yellow, pattern, cerr.Cyan, jsonFile, cerr.Red, cerr.Reset)
 		}
 	}
 }
@@ -424,7 +415,7 @@ func searchWithPatterns(jsonFile string, data map[int]Package, patterns []string
 			}
 			if isMatchFound {
 				if verbose {
-					log.Printf("%s %sGET: %s'%s'%s em %s %s- 200 OK%s\n", _APP_, Green, Yellow, strings.TrimSpace(pkg.Name), Cyan, jsonFile, Green, Reset)
+					log.Printf("%s %sGET: %s'%s'%s em %s %s- 200 OK%s\n", _APP_, cerr.Green, cerr.Yellow, strings.TrimSpace(pkg.Name), cerr.Cyan, jsonFile, cerr.Green, cerr.Reset)
 				}
 				if showJSON {
 					printJSON(pkg)
@@ -463,7 +454,7 @@ func searchWithPatterns(jsonFile string, data map[int]Package, patterns []string
 
 	if !isMatchFound {
 		if verbose {
-			log.Printf("%s %sGET: %s'%s'%s em %s %s- 404 NOK%s\n", _APP_, Red, Yellow, strings.Join(patterns, ", "), Cyan, jsonFile, Red, Reset)
+			log.Printf("%s %sGET: %s'%s'%s em %s %s- 404 NOK%s\n", _APP_, cerr.Red, cerr.Yellow, strings.Join(patterns, ", "), cerr.Cyan, jsonFile, cerr.Red, cerr.Reset)
 		}
 	}
 }
@@ -1,3 +1,7 @@
 module github.com/vcatafesta/chili-big-go/big-jq-regex
 
 go 1.23.0
+
+require colors v0.0.0
+
+replace colors => ../colors
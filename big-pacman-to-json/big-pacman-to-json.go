@@ -36,6 +36,7 @@ package main
 
 import (
 	"bufio"
+	"colors"
 	"encoding/json"
 	"fmt"
 	"log"
@@ -50,17 +51,9 @@ const (
 	_COPY_    = "Copyright (C) 2023 Vilmar Catafesta, <vcatafesta@gmail.com>"
 )
 
-// Constantes para cores ANSI
-const (
-	Reset   = "\x1b[0m"
-	Red     = "\x1b[31m"
-	Green   = "\x1b[32m"
-	Yellow  = "\x1b[33m"
-	Blue    = "\x1b[34m"
-	Magenta = "\x1b[35m"
-	Cyan    = "\x1b[36m"
-	White   = "\x1b[37m"
-)
+// Cores ANSI da saída padrão (cout) e da saída de erro (cerr). Ficam vazias
+// quando a saída não é um terminal, com NO_COLOR ou com --color=never.
+var cout, cerr = &colors.Stdout, &colors.Stderr
 
 type PackageInfoSearch struct {
 	Name        string `json:"name"`
@@ -117,6 +110,15 @@ var (
 func main() {
 	var input string
 
+	// O --color não é repassado ao pacman/paru/yay/pamac
+	colorMode, rest, err := colors.FromArgs(os.Args[1:])
+	if err != nil {
+		log.Printf("%sErro: %v%s\n", cerr.Red, err, cerr.Reset)
+		os.Exit(1)
+	}
+	colors.Setup(colorMode)
+	os.Args = append(os.Args[:1], rest...)
+
 	stat, _ := os.Stdin.Stat()
 	if (stat.Mode() & os.ModeCharDevice) == 0 {
 		scanner := bufio.NewScanner(os.Stdin)
@@ -150,7 +152,7 @@ func main() {
 			cmd := exec.Command(args[0], args[1:]...) // Executa o comando com os argumentos
 			output, err := cmd.CombinedOutput()
 			if err != nil {
-				log.Printf("%sErro ao executar o comando: %s'%s' - %s%v%s\n", Red, Cyan, os.Args[1:], Yellow, err, Reset)
+				log.Printf("%sErro ao executar o comando: %s'%s' - %s%v%s\n", cerr.Red, cerr.Cyan, os.Args[1:], cerr.Yellow, err, cerr.Reset)
 				return
 			}
 			input = string(output)
@@ -165,7 +167,7 @@ func main() {
 			usage(false)
 		}
 	} else {
-		log.Printf("%sErro: nenhuma operação/entrada especificada (use -h para obter ajuda)%s", Red, Reset)
+		log.Printf("%sErro: nenhuma operação/entrada especificada (use -h para obter ajuda)%s", cerr.Red, cerr.Reset)
 		os.Exit(1)
 	}
 }
@@ -179,22 +181,23 @@ func usage(IsValidParameter bool) {
 	}
 
 	if IsValidParameter == false {
-		log.Printf("%sErro: Parâmetro(s) inválido(s) %s'%s'%s\n", Red, Cyan, os.Args[1:], Reset)
+		log.Printf("%sErro: Parâmetro(s) inválido(s) %s'%s'%s\n", cerr.Red, cerr.Cyan, os.Args[1:], cerr.Reset)
 	}
-	fmt.Printf("Uso:%s %s%s <comandos>%s\n", Red, _APP_, Cyan, Reset)
-	fmt.Printf("%scomandos:%s\n", Cyan, Reset)
+	fmt.Printf("Uso:%s %s%s <comandos>%s\n", cout.Red, _APP_, cout.Cyan, cout.Reset)
+	fmt.Printf("%scomandos:%s\n", cout.Cyan, cout.Reset)
 	fmt.Printf("     %s -h|--help\n", _APP_)
 	fmt.Printf("     %s -v|--version\n", _APP_)
-	fmt.Printf("%s     %s pacman %s-Ss [<pacote>] [<regex>]%s\n", Yellow, _APP_, Cyan, Reset)
-	fmt.Printf("%s     %s pacman %s-Qm%s\n", Yellow, _APP_, Cyan, Reset)
-	fmt.Printf("%s     %s pacman %s-Qn%s\n", Yellow, _APP_, Cyan, Reset)
-	fmt.Printf("%s     %s pacman %s-Si [<pacote> [<...>]%s\n", Yellow, _APP_, Cyan, Reset)
-	fmt.Printf("%s     %s pacman %s-Sii [<pacote> [<...>]%s\n", Yellow, _APP_, Cyan, Reset)
-	fmt.Printf("%s     %s paru %s-Ss [<pacote> [<...>]%s\n", Yellow, _APP_, Cyan, Reset)
-	fmt.Printf("%s     %s paru %s-Ssa [<pacote> [<...>]%s\n", Yellow, _APP_, Cyan, Reset)
-	fmt.Printf("%s     %s yay %s-Ss [<pacote> [<...>]%s\n", Yellow, _APP_, Cyan, Reset)
-	fmt.Printf("%s     %s yay %s-Sii [<pacote> [<...>]%s\n", Yellow, _APP_, Cyan, Reset)
-	fmt.Printf("%s     %s pamac %s search [<pacote> [<...>]%s\n", Yellow, _APP_, Cyan, Reset)
+	fmt.Printf("     %s --color auto|always|never <comando>\n", _APP_)
+	fmt.Printf("%s     %s pacman %s-Ss [<pacote>] [<regex>]%s\n", cout.Yellow, _APP_, cout.Cyan, cout.Reset)
+	fmt.Printf("%s     %s pacman %s-Qm%s\n", cout.Yellow, _APP_, cout.Cyan, cout.Reset)
+	fmt.Printf("%s     %s pacman %s-Qn%s\n", cout.Yellow, _APP_, cout.Cyan, cout.Reset)
+	fmt.Printf("%s     %s pacman %s-Si [<pacote> [<...>]%s\n", cout.Yellow, _APP_, cout.Cyan, cout.Reset)
+	fmt.Printf("%s     %s pacman %s-Sii [<pacote> [<...>]%s\n", cout.Yellow, _APP_, cout.Cyan, cout.Reset)
+	fmt.Printf("%s     %s paru %s-Ss [<pacote> [<...>]%s\n", cout.Yellow, _APP_, cout.Cyan, cout.Reset)
+	fmt.Printf("%s     %s paru %s-Ssa [<pacote> [<...>]%s\n", cout.Yellow, _APP_, cout.Cyan, cout.Reset)
+	fmt.Printf("%s     %s yay %s-Ss [<pacote> [<...>]%s\n", cout.Yellow, _APP_, cout.Cyan, cout.Reset)
+	fmt.Printf("%s     %s yay %s-Sii [<pacote> [<...>]%s\n", cout.Yellow, _APP_, cout.Cyan, cout.Reset)
+	fmt.Printf("%s     %s pamac %s search [<pacote> [<...>]%s\n", cout.Yellow, _APP_, cout.Cyan, cout.Reset)
 	os.Exit(boolToInt(IsValidParameter))
 }
 
@@ -268,7 +271,7 @@ func ProcessOutput(output string) {
 	outputFilename := "/tmp/" + _APP_ + ".json"
 	file, err := os.Create(outputFilename)
 	if err != nil {
-		log.Printf("%sErro ao criar arquivo JSON: %v%s\n", Red, err, Reset)
+		log.Printf("%sErro ao criar arquivo JSON: %v%s\n", cerr.Red, err, cerr.Reset)
 		os.Exit(1)
 	}
 	defer file.Close()
@@ -276,14 +279,14 @@ func ProcessOutput(output string) {
 	encoder := json.NewEncoder(file)
 	encoder.SetIndent("", "    ")
 	if err := encoder.Encode(packageInfos); err != nil {
-		log.Printf("%sErro ao escrever no arquivo JSON: %v%s\n", Red, err, Reset)
+		log.Printf("%sErro ao escrever no arquivo JSON: %v%s\n", cerr.Red, err, cerr.Reset)
 		os.Exit(1)
 	}
 
 	// Converte a lista de pacotes em formato JSON
 	jsonData, err := json.Marshal(packageInfos)
 	if err != nil {
-		log.Printf("%sErro ao serializar para JSON: %v%s\n", Red, err, Reset)
+		log.Printf("%sErro ao serializar para JSON: %v%s\n", cerr.Red, err, cerr.Reset)
 		os.Exit(1)
 	}
 
@@ -0,0 +1,7 @@
+module github.com/vcatafesta/chili-big-go/big-pacman-to-json
+
+go 1.23.0
+
+require colors v0.0.0
+
+replace colors => ../colors
@@ -1,3 +1,5 @@
+//go:build ignore
+
 /*
   big-search-aur - Command-line AUR helper
     go get github.com/go-ini/ini
@@ -35,17 +35,39 @@
 package main
 
 import (
+	"bufio"
+	"colors"
+	"compress/gzip"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
-	"io/ioutil"
+	"io"
+	"io/fs"
 	"log"
+	"math/rand/v2"
+	"net"
 	"net/http"
 	"net/url"
 	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"sort"
 	"strconv"
 	"strings"
 	"sync"
 	"time"
+
+	"github.com/go-ini/ini"
+	"github.com/vcatafesta/chili-big-go/big-search-aur/internal/alpm"
+	"github.com/vcatafesta/chili-big-go/big-search-aur/internal/cache"
+	"github.com/vcatafesta/chili-big-go/big-search-aur/internal/depgraph"
+	"github.com/vcatafesta/chili-big-go/big-search-aur/internal/i18n"
+	"github.com/vcatafesta/chili-big-go/big-search-aur/internal/picker"
+	"github.com/vcatafesta/chili-big-go/big-search-aur/internal/snapshot"
+	"srcinfo"
+	"vercmp"
 )
 
 const (
@@ -55,50 +77,105 @@ const (
 	_COPYRIGHT_ = "Copyright (C) 2024 Vilmar Catafesta, <vcatafesta@gmail.com>"
 )
 
-// Constantes para cores ANSI
-const (
-	reset   = "\x1b[0m"
-	red     = "\x1b[31m"
-	green   = "\x1b[32m"
-	yellow  = "\x1b[33m"
-	blue    = "\x1b[34m"
-	magenta = "\x1b[35m"
-	cyan    = "\x1b[36m"
-	white   = "\x1b[37m"
-	Reset   = "\x1b[0m"
-	Red     = "\x1b[31m"
-	Green   = "\x1b[32m"
-	Yellow  = "\x1b[33m"
-	Blue    = "\x1b[34m"
-	Magenta = "\x1b[35m"
-	Cyan    = "\x1b[36m"
-	White   = "\x1b[37m"
-)
+// Cores ANSI da saída padrão (cout) e da saída de erro (cerr). Ficam vazias
+// quando a saída não é um terminal, com NO_COLOR ou com --color=never.
+var cout, cerr = &colors.Stdout, &colors.Stderr
 
+// Package contém todos os campos retornados pelo AUR RPC v5. As listas
+// (Depends, License, ...) só vêm preenchidas nas consultas do tipo info.
 type Package struct {
-	Name        string  `json:"Name"`
-	Version     string  `json:"Version"`
-	Description string  `json:"Description"`
-	Maintainer  string  `json:"Maintainer"`
-	NumVotes    int     `json:"NumVotes"`
-	Popularity  float64 `json:"Popularity"`
-	URL         string  `json:"URL"`
-	fullURL     string
-	count       int
+	ID             int        `json:"ID"`
+	Name           string     `json:"Name"`
+	PackageBaseID  int        `json:"PackageBaseID"`
+	PackageBase    string     `json:"PackageBase"`
+	Version        string     `json:"Version"`
+	Description    string     `json:"Description"`
+	URL            string     `json:"URL"`
+	NumVotes       int        `json:"NumVotes"`
+	Popularity     float64    `json:"Popularity"`
+	OutOfDate      *int64     `json:"OutOfDate"` // null ou data (unix) em que foi marcado como desatualizado
+	Maintainer     string     `json:"Maintainer"`
+	Submitter      string     `json:"Submitter,omitempty"`
+	FirstSubmitted int64      `json:"FirstSubmitted"`
+	LastModified   int64      `json:"LastModified"`
+	URLPath        string     `json:"URLPath"`
+	Depends        []string   `json:"Depends,omitempty"`
+	MakeDepends    []string   `json:"MakeDepends,omitempty"`
+	OptDepends     []string   `json:"OptDepends,omitempty"`
+	CheckDepends   []string   `json:"CheckDepends,omitempty"`
+	Conflicts      []string   `json:"Conflicts,omitempty"`
+	Provides       []string   `json:"Provides,omitempty"`
+	Replaces       []string   `json:"Replaces,omitempty"`
+	Groups         []string   `json:"Groups,omitempty"`
+	License        []string   `json:"License,omitempty"`
+	Keywords       []string   `json:"Keywords,omitempty"`
+	CoMaintainers  []string   `json:"CoMaintainers,omitempty"`
+	Relations      []Relation `json:"Relations,omitempty"` // preenchido apenas pelo --rdeps
+	fullURL        string
+	count          int
+}
+
+// Relation diz por que o pacote apareceu no --rdeps: o tipo de dependência
+// (depends, makedepends, optdepends ou checkdepends) e a entrada exata, com a
+// restrição de versão, ex: "glibc>=2.38"
+type Relation struct {
+	Kind       string `json:"Kind"`
+	Dependency string `json:"Dependency"`
 }
 
-type CacheEntry struct {
-	Results   []Package
-	Timestamp time.Time
+// Tipos de dependência consultados pelo --rdeps, na ordem em que são listados
+var relationKinds = []string{"depends", "makedepends", "optdepends", "checkdepends"}
+
+// Campos na ordem usada pelas saídas --raw e --pairs. Os sete primeiros são
+// os campos históricos, mantidos na frente para não quebrar scripts que
+// leem as colunas por posição.
+var allFields = []string{
+	"Name", "Version", "Description", "Maintainer", "NumVotes", "Popularity", "URL",
+	"ID", "PackageBaseID", "PackageBase", "OutOfDate", "Submitter", "FirstSubmitted", "LastModified", "URLPath",
+	"Depends", "MakeDepends", "OptDepends", "CheckDepends", "Conflicts", "Provides", "Replaces",
+	"Groups", "License", "Keywords", "CoMaintainers",
 }
 
+// Campos escolhidos com --fields (vazio = todos)
+var selectedFields []string
+
+// Cache em disco compartilhado entre execuções ($XDG_CACHE_HOME/big-search-aur)
 var (
-	cache      = make(map[string]CacheEntry)
-	cacheMutex = sync.Mutex{}
-	cacheTTL   = time.Minute * 5
+	diskCache = &cache.Cache{
+		Dir:        cache.DefaultDir(_APP_),
+		TTL:        time.Minute * 5,
+		MaxBytes:   32 << 20, // 32 MiB
+		MaxEntries: 4096,
+	}
+	noCache      bool // --no-cache: não lê nem grava o cache
+	refreshCache bool // --refresh: ignora o cache na leitura, mas grava o resultado
+	cacheStats   bool // --cache-stats: mostra o relatório do cache
 )
 
-const baseURL = "https://aur.archlinux.org/rpc"
+// Endereço padrão do AUR RPC. Pode ser trocado (em ordem de prioridade) pelo
+// --aur-url, pela variável BIG_SEARCH_AUR_URL ou pela chave aur_url do arquivo
+// de configuração, para usar o espelho do Chili ou um servidor local.
+const defaultBaseURL = "https://aur.archlinux.org/rpc"
+
+var baseURL = defaultBaseURL
+
+// Tamanho máximo de URI aceito pelo aurweb; os lotes do -Si respeitam esse limite
+const maxURLLength = 4443
+
+// Início da execução, para o elapsed_ms da saída --json
+var startTime = time.Now()
+
+// Nomes pedidos com -Si que não existem no AUR
+var notFoundNames []string
+
+// Código de saída quando a consulta de algum termo ao AUR falhou
+const exitRequestFailed = 2
+
+// Código de saída do --deps-tree quando há ciclos ou dependências não resolvidas
+const exitDepsProblem = 3
+
+// Código de saída quando o --pick é cancelado (Esc ou Ctrl-C), como no fzf
+const exitPickCancelled = 130
 
 // Declaração da variável global
 var verbose bool
@@ -112,17 +189,130 @@ var outputFormat string
 var separator string = "|"
 var limit int = -1 // Usar -1 para indicar que não há limite
 var searchMode string
+var allTerms bool   // --all-terms: todos os termos precisam coincidir (AND)
+var sortMode string // --sort: votes, popularity, name, modified ou relevance
+var reverseSort bool
+var pick bool                   // --pick: seletor interativo dos resultados
+var dbPath = alpm.DefaultDBPath // --dbpath: diretório dos bancos do pacman, usado pelo -Qua e --deps-tree
+var force bool                  // --force: o -G baixa de novo mesmo os snapshots atualizados
+var depsTree bool               // --deps-tree: resolve as dependências do -Si e mostra a ordem de compilação
 var args []string
-var optionToField map[string]string
+var optionToField = map[string]string{
+	"--by-name":         "name",
+	"--by-name-desc":    "name-desc",
+	"--by-maintainer":   "maintainer",
+	"--by-depends":      "depends",
+	"--by-makedepends":  "makedepends",
+	"--by-optdepends":   "optdepends",
+	"--by-checkdepends": "checkdepends",
+}
+
+// Tipos de argumento das opções, usados pelos scripts do --completion
+const (
+	argNone   = ""       // a opção não tem argumento
+	argValue  = "value"  // texto ou número livre
+	argChoice = "choice" // um dos Values
+	argList   = "list"   // Values separados por vírgula
+	argDir    = "dir"    // diretório
+)
+
+// flagDef descreve uma opção da linha de comando. A tabela flagDefs gera o
+// printUsage e os scripts de completação; o parseArgs trata cada opção.
+type flagDef struct {
+	Names  []string // na ordem mostrada no help, ex: {"-Ss", "--search"}
+	Usage  string   // argumentos das operações (-Ss, -Si, ...), mostrados em verde no help
+	Arg    string   // argNone, argValue, argChoice, argList ou argDir
+	Values []string // valores aceitos por argChoice e argList
+	Desc   string
+	Hidden bool // uso interno: fora do help e da completação
+}
+
+var flagDefs = []flagDef{
+	{Names: []string{"-Ss", "--search"}, Usage: gettext("<palavra-chave> ... <opção>"), Desc: gettext("pesquisa no repositório AUR por palavras coincidentes")},
+	{Names: []string{"-Si", "--info"}, Usage: gettext("<palavra-chave> ... <opção>"), Desc: gettext("pesquisa no repositório AUR por palavras coincidentes")},
+	{Names: []string{"-G", "--getpkgbuild"}, Usage: gettext("<pacote> ... <opção>"), Desc: gettext("baixa e extrai o snapshot (PKGBUILD) em ./<PackageBase>")},
+	{Names: []string{"--srcinfo"}, Usage: gettext("<pacote|arquivo|dir> ..."), Desc: gettext("mostra o .SRCINFO em JSON: pacotes, sources e checksums")},
+	{Names: []string{"-Qua", "--upgrades"}, Usage: gettext("<opção>"), Desc: gettext("lista atualizações dos pacotes instalados que vieram do AUR")},
+	{Names: []string{"--by-name"}, Desc: gettext("Pesquisa pelo nome do pacote apenas (padrão)")},
+	{Names: []string{"--by-name-desc"}, Desc: gettext("Pesquisa pelo nome e descrição do pacote")},
+	{Names: []string{"--by-maintainer"}, Desc: gettext("Pesquisa pelo mantenedor do pacote")},
+	{Names: []string{"--by-depends"}, Desc: gettext("Pesquisa pacotes que são dependências por palavras-chaves")},
+	{Names: []string{"--by-makedepends"}, Desc: gettext("Pesquisa pacotes que são dependências para compilação por palavras-chaves")},
+	{Names: []string{"--by-optdepends"}, Desc: gettext("Pesquisa pacotes que são dependências opcionais por palavras-chaves")},
+	{Names: []string{"--by-checkdepends"}, Desc: gettext("Pesquisa pacotes que são dependências para verificação por palavras-chaves")},
+	{Names: []string{"--all-terms"}, Desc: gettext("Mostra apenas pacotes que coincidem com todas as palavras-chave (AND)")},
+	{Names: []string{"--sort"}, Arg: argChoice, Values: sortModes, Desc: gettext("Ordena por votes, popularity, name, modified ou relevance")},
+	{Names: []string{"--reverse"}, Desc: gettext("Inverte a ordenação do --sort")},
+	{Names: []string{"--pick"}, Desc: gettext("Seletor interativo (Tab marca, Enter confirma); mostra os nomes escolhidos")},
+	{Names: []string{"--dbpath"}, Arg: argDir, Desc: fmt.Sprintf(gettext("Diretório dos bancos de dados do pacman usado pelo -Qua (padrão é %s)"), alpm.DefaultDBPath)},
+	{Names: []string{"--force"}, Desc: gettext("Com -G, baixa e extrai de novo mesmo os snapshots já atualizados")},
+	{Names: []string{"--rdeps"}, Desc: gettext("Pacotes do AUR que dependem das palavras-chave (depends, makedepends, optdepends e checkdepends), com o campo Relations")},
+	{Names: []string{"--deps-tree"}, Desc: gettext("Resolve as dependências (Depends e MakeDepends) dos pacotes do -Si no AUR e mostra a ordem de compilação")},
+	{Names: []string{"--json"}, Desc: gettext("Saída em formato JSON (padrão): objeto com query, results, errors e elapsed_ms")},
+	{Names: []string{"--ndjson"}, Desc: gettext("Um pacote JSON por linha, mostrado assim que chega (sem --sort)")},
+	{Names: []string{"--raw"}, Desc: gettext("Saída formatada como texto simples com todos os campos (util para usar com mapfile/read do bash)")},
+	{Names: []string{"--pairs"}, Desc: gettext("Usa o formato de saída texto chave='valor' (util para usar com mapfile/read do bash)")},
+	{Names: []string{"--text"}, Desc: gettext("Saída em texto: árvore no --deps-tree, lista no -Qua")},
+	{Names: []string{"--dot"}, Desc: gettext("Grafo do --deps-tree no formato Graphviz DOT (ex: | dot -Tsvg > deps.svg)")},
+	{Names: []string{"--fields"}, Arg: argList, Values: append(allFields[:len(allFields):len(allFields)], "Relations"), Desc: gettext("Lista de campos da saída separados por vírgula (ex: Name,Version,Depends)")},
+	{Names: []string{"--shell"}, Desc: gettext("Uma linha 'declare -A pkg=(...)' por pacote, com os valores escapados para eval")},
+	{Names: []string{"-0", "--null"}, Desc: gettext("Cada campo terminado por NUL, todos os pacotes com os mesmos campos (para mapfile -d '')")},
+	{Names: []string{"--sep"}, Arg: argValue, Desc: gettext("Separador dos campos na saída raw (padrão é '=')")},
+	{Names: []string{"--limit"}, Arg: argValue, Desc: gettext("Limite de pacotes encontrados (aplicado após o --sort)")},
+	{Names: []string{"--profile"}, Arg: argValue, Desc: gettext("Usa a seção [perfil] do arquivo de configuração")},
+	{Names: []string{"--aur-url"}, Arg: argValue, Desc: fmt.Sprintf(gettext("Endereço do AUR RPC (padrão é %s)"), defaultBaseURL)},
+	{Names: []string{"--timeout"}, Arg: argValue, Desc: gettext("Tempo máximo de cada requisição em segundos (padrão é 30)")},
+	{Names: []string{"--connect-timeout"}, Arg: argValue, Desc: gettext("Tempo máximo para conectar em segundos (padrão é 10)")},
+	{Names: []string{"--retries"}, Arg: argValue, Desc: gettext("Novas tentativas em caso de 429, 5xx ou erro de rede (padrão é 3)")},
+	{Names: []string{"--max-requests"}, Arg: argValue, Desc: gettext("Máximo de requisições simultâneas ao AUR (padrão é 4)")},
+	{Names: []string{"--verbose"}, Desc: gettext("Liga modo verboso")},
+	{Names: []string{"--color"}, Arg: argChoice, Values: colors.Modes, Desc: gettext("Cores na saída: auto (só em terminal e sem NO_COLOR, padrão), always ou never")},
+	{Names: []string{"--no-cache"}, Desc: gettext("Não usa o cache em disco (nem leitura, nem gravação)")},
+	{Names: []string{"--refresh"}, Desc: gettext("Ignora o cache e atualiza-o com a resposta do AUR")},
+	{Names: []string{"--cache-ttl"}, Arg: argValue, Desc: gettext("Validade das entradas do cache em segundos (padrão é 300)")},
+	{Names: []string{"--cache-stats"}, Desc: gettext("Mostra estatísticas do cache em disco")},
+	{Names: []string{"--completion"}, Arg: argChoice, Values: completionShells, Desc: fmt.Sprintf(gettext("Gera o script de completação do bash, zsh ou fish (ex: source <(%s --completion bash))"), _APP_)},
+	{Names: []string{"--complete-packages"}, Arg: argValue, Hidden: true, Desc: gettext("Nomes de pacotes que começam com o argumento, usado pelos scripts de completação")},
+	{Names: []string{"--bash"}, Desc: gettext("Mostra exemplo de uso com bash")},
+	{Names: []string{"-V", "--version"}, Desc: gettext("Mostra a versão do aplicativo")},
+	{Names: []string{"--help"}, Desc: gettext("Este help")},
+}
+
 var p = fmt.Println
 
+// Tradução das mensagens (internal/i18n); os msgid são extraídos com
+// xgettext --keyword=gettext
+var gettext = i18n.Gettext
+
 // Inline
-var msgError = func(msg string) { p(Red + msg + Reset) }
+var msgError = func(msg string) { fmt.Fprintln(os.Stderr, cerr.Red+msg+cerr.Reset) }
 var echo = func(args ...interface{}) { p(args...) }
-var logError = func(args ...interface{}) { log.Println(Red + fmt.Sprint(args...) + Reset) }
+var logError = func(args ...interface{}) { log.Println(cerr.Red + fmt.Sprint(args...) + cerr.Reset) }
 
 func main() {
-	args = os.Args[1:]
+	// O --color da linha de comando prevalece sobre a chave color do
+	// arquivo de configuração
+	colorMode, rest, err := colors.FromArgs(os.Args[1:])
+	if err != nil {
+		logError(gettext("Erro: "), err)
+		os.Exit(1)
+	}
+	profile, ok := profileArg(os.Args[1:])
+	if !ok {
+		logError(fmt.Sprintf(gettext("Erro: %s requer um argumento"), "--profile"))
+		os.Exit(1)
+	}
+	if !loadConfig(profile) {
+		os.Exit(1)
+	}
+	if len(rest) != len(os.Args[1:]) {
+		colors.Setup(colorMode)
+	}
+	if envURL := os.Getenv("BIG_SEARCH_AUR_URL"); envURL != "" && !setBaseURL(envURL) {
+		logError(fmt.Sprintf(gettext("Aviso: BIG_SEARCH_AUR_URL ignorada, não é uma URL http(s) válida: %s"), envURL))
+	}
+
+	args = rest
 	nlenArgs = len(args)
 	if nlenArgs < 1 {
 		printUsage()
@@ -130,25 +320,28 @@ func main() {
 	}
 
 	if parseArgs() {
-		if searchMode == "search" && len(searchTerms) == 0 {
-			echo(Red + "Erro: Nenhuma palavra-chave de busca fornecida" + Reset)
+		if searchMode != "info" && searchMode != "upgrade" && len(searchTerms) == 0 {
+			msgError(gettext("Erro: Nenhuma palavra-chave de busca fornecida"))
 			return
 		}
-		runSearchPackages()
+		if searchMode == "upgrade" {
+			runUpgradeCheck()
+		} else if searchMode == "getpkgbuild" {
+			runGetPkgbuild()
+		} else if searchMode == "srcinfo" {
+			runSrcinfo()
+		} else if depsTree {
+			runDepsTree()
+		} else {
+			runSearchPackages()
+		}
+		if len(failures) > 0 {
+			os.Exit(exitRequestFailed)
+		}
 	}
 }
 
 func parseArgs() bool {
-	optionToField = map[string]string{
-		"--by-name":         "name",
-		"--by-name-desc":    "name-desc",
-		"--by-maintainer":   "maintainer",
-		"--by-depends":      "depends",
-		"--by-makedepends":  "makedepends",
-		"--by-optdepends":   "optdepends",
-		"--by-checkdepends": "checkdepends",
-	}
-
 	for i := 0; i < nlenArgs; i++ {
 		//		logError("args[", i, "]", args[i])
 		switch args[i] {
@@ -156,43 +349,166 @@ func parseArgs() bool {
 			searchMode = "search"
 		case "-Si", "--info":
 			searchMode = "info"
-		case "--json", "--raw", "--pairs":
+		case "-Qua", "--upgrades":
+			searchMode = "upgrade"
+		case "--dbpath":
+			if i+1 < nlenArgs && !strings.HasPrefix(args[i+1], "--") {
+				dbPath = args[i+1]
+				i++
+			} else {
+				logError(gettext("Erro: --dbpath requer um diretório"))
+				return false
+			}
+		case "--json", "--ndjson", "--raw", "--pairs", "--shell", "--null", "--text", "--dot":
 			outputFormat = args[i]
+		case "-0":
+			outputFormat = "--null"
 		case "--sep":
 			if i+1 < nlenArgs && !strings.HasPrefix(args[i+1], "--") {
 				separator = args[i+1]
 				i++
 			} else {
-				logError("Erro: --sep requer um argumento válido.")
+				logError(gettext("Erro: --sep requer um argumento válido."))
+				return false
+			}
+		case "--fields":
+			if i+1 < nlenArgs && !strings.HasPrefix(args[i+1], "--") {
+				fields, err := parseFields(args[i+1])
+				if err != nil {
+					logError(gettext("Erro: "), err)
+					return false
+				}
+				selectedFields = fields
+				i++
+			} else {
+				logError(gettext("Erro: --fields requer uma lista de campos separados por vírgula"))
+				return false
+			}
+		case "--aur-url":
+			if i+1 < nlenArgs && !strings.HasPrefix(args[i+1], "--") && setBaseURL(args[i+1]) {
+				i++
+			} else {
+				logError(gettext("Erro: --aur-url requer uma URL http(s) válida"))
+				return false
+			}
+		case "--timeout":
+			seconds, ok := intArg(&i, 1)
+			if !ok {
+				return false
+			}
+			requestTimeout = time.Duration(seconds) * time.Second
+		case "--connect-timeout":
+			seconds, ok := intArg(&i, 1)
+			if !ok {
+				return false
+			}
+			connectTimeout = time.Duration(seconds) * time.Second
+		case "--retries":
+			retries, ok := intArg(&i, 0)
+			if !ok {
+				return false
+			}
+			maxRetries = retries
+		case "--max-requests":
+			requests, ok := intArg(&i, 1)
+			if !ok {
+				return false
+			}
+			maxRequests = requests
+		case "--all-terms":
+			allTerms = true
+		case "--sort":
+			if i+1 < nlenArgs && isSortMode(args[i+1]) {
+				sortMode = args[i+1]
+				i++
+			} else {
+				logError(gettext("Erro: --sort requer um dos modos: "), strings.Join(sortModes, ", "))
+				return false
+			}
+		case "--reverse":
+			reverseSort = true
+		case "--pick":
+			pick = true
+		case "-G", "--getpkgbuild":
+			searchMode = "getpkgbuild"
+		case "--profile":
+			// Já aplicado pelo loadConfig; aqui só pula o valor
+			if i+1 >= nlenArgs {
+				logError(fmt.Sprintf(gettext("Erro: %s requer um argumento"), args[i]))
 				return false
 			}
+			i++
+		case "--srcinfo":
+			searchMode = "srcinfo"
+		case "--force":
+			force = true
+		case "--rdeps":
+			searchMode = "rdeps"
+		case "--deps-tree":
+			depsTree = true
+			searchMode = "info"
 		case "--verbose":
 			verbose = true
+		case "--no-cache":
+			noCache = true
+		case "--refresh":
+			refreshCache = true
+		case "--cache-stats":
+			cacheStats = true
+		case "--cache-ttl":
+			if i+1 < nlenArgs {
+				seconds, err := strconv.Atoi(args[i+1])
+				if err != nil || seconds < 0 {
+					logError(gettext("Erro: --cache-ttl requer um número de segundos válido"))
+					return false
+				}
+				diskCache.TTL = time.Duration(seconds) * time.Second
+				i++
+			} else {
+				logError(gettext("Erro: --cache-ttl requer um argumento"))
+				return false
+			}
 		case "--help":
 			printUsage()
 			return false
 		case "--bash":
 			helpBash()
 			return false
+		case "--completion":
+			if i+1 < nlenArgs && slices.Contains(completionShells, args[i+1]) {
+				fmt.Print(completionScript(args[i+1]))
+			} else {
+				logError(gettext("Erro: --completion requer um dos shells: "), strings.Join(completionShells, ", "))
+			}
+			return false
+		case "--complete-packages":
+			prefix := ""
+			if i+1 < nlenArgs {
+				prefix = args[i+1]
+			}
+			for _, name := range completePackages(prefix) {
+				p(name)
+			}
+			return false
     case "-V", "--version":
-      p(Red + _APP_ + " - " + _PKGDESC_ + Reset)
-      p(Cyan + _APP_ + " - v" + _VERSION_ + Reset)
-      p("   " + _COPYRIGHT_ + Reset)
+      p(cout.Red + _APP_ + " - " + _PKGDESC_ + cout.Reset)
+      p(cout.Cyan + _APP_ + " - v" + _VERSION_ + cout.Reset)
+      p("   " + _COPYRIGHT_ + cout.Reset)
       p("")
-      p("   Este programa pode ser redistribuído livremente")
-      p("   sob os termos da Licença Pública Geral GNU.")
+      p(gettext("   Este programa pode ser redistribuído livremente"))
+      p(gettext("   sob os termos da Licença Pública Geral GNU."))
       os.Exit(0)
 		case "--limit":
 			if i+1 < nlenArgs {
 				parsedLimit, err := strconv.Atoi(args[i+1])
 				if err != nil || parsedLimit < 1 {
-					logError("Erro: --limit requer um número positivo")
+					logError(gettext("Erro: --limit requer um número positivo"))
 					return false
 				}
 				limit = parsedLimit
 				i++
 			} else {
-				logError("Erro: --limit requer um argumento")
+				logError(gettext("Erro: --limit requer um argumento"))
 				return false
 			}
 		default:
@@ -203,10 +519,267 @@ func parseArgs() bool {
 			}
 		}
 	}
+	if cacheStats {
+		printCacheStats()
+		return false
+	}
 	if searchMode == "" && len(searchTerms) == 0 {
-		logError("Erro: requer um argumento de busca válido, -Si ou -Ss")
+		logError(gettext("Erro: requer um argumento de busca válido, -Si, -Ss ou -Qua"))
+		return false
+	}
+	return true
+}
+
+// intArg lê o número que segue a opção args[*i], que deve ser >= min
+func intArg(i *int, min int) (int, bool) {
+	option := args[*i]
+	if *i+1 >= nlenArgs {
+		logError(fmt.Sprintf(gettext("Erro: %s requer um argumento"), option))
+		return 0, false
+	}
+	value, err := strconv.Atoi(args[*i+1])
+	if err != nil || value < min {
+		logError(fmt.Sprintf(gettext("Erro: %s requer um número maior ou igual a %d"), option, min))
+		return 0, false
+	}
+	*i++
+	return value, true
+}
+
+// configFiles retorna os arquivos de configuração na ordem de leitura; os
+// valores do usuário sobrescrevem os do sistema
+func configFiles() []string {
+	files := []string{"/etc/" + _APP_ + ".conf"}
+	configDir := os.Getenv("XDG_CONFIG_HOME")
+	if configDir == "" {
+		if home, err := os.UserHomeDir(); err == nil {
+			configDir = filepath.Join(home, ".config")
+		}
+	}
+	if configDir != "" {
+		files = append(files, filepath.Join(configDir, _APP_+".conf"))
+	}
+	return files
+}
+
+// loadConfig lê os arquivos de configuração existentes. Primeiro são
+// aplicadas as chaves fora de seção (sistema e depois usuário) e, por cima,
+// as da seção [perfil] escolhida com --profile ou com a chave profile. As
+// opções da linha de comando são lidas depois e sempre prevalecem.
+//
+// Exemplo de ~/.config/big-search-aur.conf:
+//
+//	format = raw
+//	sep = |
+//	limit = 50
+//
+//	[bigstore]
+//	format = json
+//	by = name-desc
+//	fields = Name,Version,Description,NumVotes
+//
+// Comentários só no início da linha; use aspas para espaços (sep = " ").
+func loadConfig(profile string) bool {
+	type configFile struct {
+		path string
+		cfg  *ini.File
+	}
+	var files []configFile
+	fromFlag := profile != ""
+	for _, filePath := range configFiles() {
+		if _, err := os.Stat(filePath); err != nil {
+			continue
+		}
+		// Sem comentários no fim da linha: ';' e '#' são separadores válidos
+		cfg, err := ini.LoadSources(ini.LoadOptions{IgnoreInlineComment: true}, filePath)
+		if err != nil {
+			logError(fmt.Sprintf(gettext("Erro ao ler o arquivo de configuração %s: %v"), filePath, err))
+			continue
+		}
+		files = append(files, configFile{filePath, cfg})
+		if value := cfg.Section("").Key("profile").String(); value != "" && !fromFlag {
+			profile = value
+		}
+	}
+
+	for _, file := range files {
+		applyConfig(file.cfg.Section(""), file.path)
+	}
+	if profile == "" {
+		return true
+	}
+	found := false
+	for _, file := range files {
+		if section, err := file.cfg.GetSection(profile); err == nil {
+			applyConfig(section, file.path)
+			found = true
+		}
+	}
+	if !found {
+		logError(fmt.Sprintf(gettext("Erro: perfil '%s' não encontrado em %s"), profile, strings.Join(configFiles(), gettext(" ou "))))
+	}
+	return found
+}
+
+// profileArg retorna o valor do --profile, lido antes das outras opções
+// para que o perfil seja aplicado antes delas. ok é false quando o --profile
+// é o último argumento, sem valor.
+func profileArg(args []string) (profile string, ok bool) {
+	for i := range args {
+		if args[i] == "--profile" {
+			if i+1 >= len(args) {
+				return "", false
+			}
+			return args[i+1], true
+		}
+	}
+	return "", true
+}
+
+// applyConfig aplica as chaves de uma seção; erros são avisados e a chave
+// ignorada
+func applyConfig(section *ini.Section, filePath string) {
+	for _, key := range section.Keys() {
+		if err := applyConfigKey(key.Name(), key.String()); err != nil {
+			logError(fmt.Sprintf(gettext("Erro em %s [%s] %s: %v"), filePath, section.Name(), key.Name(), err))
+		}
+	}
+}
+
+// applyConfigKey aplica uma chave do arquivo de configuração. Os nomes
+// seguem as opções da linha de comando, sem os "--" e com "_" no lugar de "-".
+func applyConfigKey(name, value string) error {
+	parseBool := func() (bool, error) {
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return false, fmt.Errorf(gettext("valor booleano inválido '%s'"), value)
+		}
+		return b, nil
+	}
+	parseInt := func(min int) (int, error) {
+		n, err := strconv.Atoi(value)
+		if err != nil || n < min {
+			return 0, fmt.Errorf(gettext("requer um número maior ou igual a %d"), min)
+		}
+		return n, nil
+	}
+
+	var err error
+	switch name {
+	case "profile":
+		// já tratado pelo loadConfig
+	case "aur_url":
+		if !setBaseURL(value) {
+			err = fmt.Errorf(gettext("URL inválida '%s'"), value)
+		}
+	case "format":
+		switch value {
+		case "json", "ndjson", "raw", "pairs", "shell", "null", "text", "dot":
+			outputFormat = "--" + value
+		default:
+			err = fmt.Errorf(gettext("formato desconhecido '%s'"), value)
+		}
+	case "sep", "separator":
+		separator = value
+	case "limit":
+		if n, e := parseInt(1); e == nil {
+			limit = n
+		} else {
+			err = e
+		}
+	case "by":
+		if _, ok := optionToField["--by-"+value]; ok {
+			searchField = value
+		} else {
+			err = fmt.Errorf(gettext("campo de busca desconhecido '%s'"), value)
+		}
+	case "fields":
+		if fields, e := parseFields(value); e == nil {
+			selectedFields = fields
+		} else {
+			err = e
+		}
+	case "sort":
+		if isSortMode(value) {
+			sortMode = value
+		} else {
+			err = fmt.Errorf(gettext("modo de ordenação desconhecido '%s'"), value)
+		}
+	case "reverse":
+		if b, e := parseBool(); e == nil {
+			reverseSort = b
+		} else {
+			err = e
+		}
+	case "all_terms":
+		if b, e := parseBool(); e == nil {
+			allTerms = b
+		} else {
+			err = e
+		}
+	case "color":
+		if mode, e := colors.ParseMode(value); e == nil {
+			colors.Setup(mode)
+		} else {
+			err = e
+		}
+	case "verbose":
+		if b, e := parseBool(); e == nil {
+			verbose = b
+		} else {
+			err = e
+		}
+	case "no_cache":
+		if b, e := parseBool(); e == nil {
+			noCache = b
+		} else {
+			err = e
+		}
+	case "cache_ttl":
+		if n, e := parseInt(0); e == nil {
+			diskCache.TTL = time.Duration(n) * time.Second
+		} else {
+			err = e
+		}
+	case "timeout":
+		if n, e := parseInt(1); e == nil {
+			requestTimeout = time.Duration(n) * time.Second
+		} else {
+			err = e
+		}
+	case "connect_timeout":
+		if n, e := parseInt(1); e == nil {
+			connectTimeout = time.Duration(n) * time.Second
+		} else {
+			err = e
+		}
+	case "retries":
+		if n, e := parseInt(0); e == nil {
+			maxRetries = n
+		} else {
+			err = e
+		}
+	case "max_requests":
+		if n, e := parseInt(1); e == nil {
+			maxRequests = n
+		} else {
+			err = e
+		}
+	case "dbpath":
+		dbPath = value
+	default:
+		err = errors.New(gettext("chave desconhecida"))
+	}
+	return err
+}
+
+// setBaseURL troca o endereço do AUR RPC, aceitando apenas URLs http(s)
+func setBaseURL(rawURL string) bool {
+	parsed, err := url.Parse(rawURL)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
 		return false
 	}
+	baseURL = strings.TrimSuffix(rawURL, "/")
 	return true
 }
 
@@ -215,25 +788,32 @@ func helpBash() {
 #!/usr/bin/env bash
 # -*- coding: utf-8 -*-
 
-by_pairs_with_read() {
-  # Processar cada linha e atribuir valores às variáveis
+by_shell_with_eval() {
+  # Cada linha é um 'declare -A pkg=(...)' com os valores já escapados
   while read -r line; do
-    # Substitui "=" por "_=" e avalia a linha
-    eval "${line//=/_=}"
-
-    # Se a linha estiver vazia, pula para a próxima iteração
-    if [[ -n "$line" ]]; then
-      echo "Name: $Name_"
-      echo "Version: $Version_"
-      echo "Description: $Description_"
-      echo "Maintainer: $Maintainer_"
-      echo "NumVotes: $NumVotes_"
-      echo "Popularity: $Popularity_"
-      echo "URL: $URL_"
-      echo ""
-    fi
-    unset Name_ Version_ Description_ Maintainer_ NumVotes_ Popularity_ URL_
-  done < <(big-search-aur -Si elisa-git brave-bin --pairs --sep '=')
+    eval "$line"
+    echo "Name: ${pkg[Name]}"
+    echo "Version: ${pkg[Version]}"
+    echo "Description: ${pkg[Description]}"
+    echo "Maintainer: ${pkg[Maintainer]}"
+    echo "NumVotes: ${pkg[NumVotes]}"
+    echo "Popularity: ${pkg[Popularity]}"
+    echo "URL: ${pkg[URL]}"
+    echo ""
+  done < <(big-search-aur -Si elisa-git brave-bin --shell)
+}
+
+by_null_with_mapfile() {
+  # Cada campo termina com NUL; todos os pacotes têm os mesmos campos
+  local fields=(Name Version Description)
+  local nfields=${#fields[@]}
+  mapfile -d '' values < <(big-search-aur -Ss brave --null --fields Name,Version,Description)
+  for ((i = 0; i < ${#values[@]}; i += nfields)); do
+    echo "Name: ${values[i]}"
+    echo "Version: ${values[i + 1]}"
+    echo "Description: ${values[i + 2]}"
+    echo ""
+  done
 }
 
 by_raw_with_mapfile_read() {
@@ -243,7 +823,8 @@ by_raw_with_mapfile_read() {
   mapfile -t packages <<<"$output"
   # Itera sobre cada linha (pacote)
   for package_line in "${packages[@]}"; do
-    IFS="$separator" read -r name version description maintainer num_votes popularity url full_url count <<<"$package_line"
+    # Sem -r: o separador que aparece dentro de um campo vem escapado com '\'
+    IFS="$separator" read name version description maintainer num_votes popularity url count <<<"$package_line"
     echo "Linha do pacote: $package_line"
 
     # Exibe as informações do pacote
@@ -254,306 +835,2196 @@ by_raw_with_mapfile_read() {
     echo "NumVotes: $num_votes"
     echo "Popularity: $popularity"
     echo "URL: $url"
-    echo "fullURL: $full_url"
     echo "Count: $count"
     echo "###############################################################################################################"
   done
 }
 
-#by_raw_with_mapfile_read() {
-by_pairs_with_read() {
+#by_raw_with_mapfile_read
+#by_null_with_mapfile
+by_shell_with_eval
 
 `
-	p(Cyan + text + Reset)
+	p(cout.Cyan + text + cout.Reset)
 }
 
 func printUsage() {
-	p("Uso:")
-	fmt.Printf("%s%-20s %s%s%s%s%s\n", blue, "  -Ss, --search", green, "<palavra-chave> ... <opção>", cyan, " # pesquisa no repositório AUR por palavras coincidentes", reset)
-	fmt.Printf("%s%-20s %s%s%s%s%s\n", blue, "  -Si, --info", green, "<palavra-chave> ... <opção>", cyan, " # pesquisa no repositório AUR por palavras coincidentes", reset)
-	p("    <palavras-chave> são os termos/pacotes de busca")
-	p("    <opção> podem ser:")
-	fmt.Printf("%s%-20s %s%s%s\n", blue, "  --by-name", reset, "Pesquisa pelo nome do pacote apenas (padrão)", reset)
-	fmt.Printf("%s%-20s %s%s%s\n", blue, "  --by-name-desc", reset, "Pesquisa pelo nome e descrição do pacote", reset)
-	fmt.Printf("%s%-20s %s%s%s\n", blue, "  --by-maintainer", reset, "Pesquisa pelo mantenedor do pacote", reset)
-	fmt.Printf("%s%-20s %s%s%s\n", blue, "  --by-dependsr", reset, "Pesquisa pacotes que são dependências por palavras-chaves", reset)
-	fmt.Printf("%s%-20s %s%s%s\n", blue, "  --by-makedependsr", reset, "Pesquisa pacotes que são dependências para compilação por palavras-chaves", reset)
-	fmt.Printf("%s%-20s %s%s%s\n", blue, "  --by-optdependsr", reset, "Pesquisa pacotes que são dependências opcionais por palavras-chaves", reset)
-	fmt.Printf("%s%-20s %s%s%s\n", blue, "  --by-checkdependsr", reset, "Pesquisa pacotes que são dependências para verificação por palavras-chaves", reset)
-	fmt.Printf("%s%-20s %s%s%s\n", blue, "  --json", reset, "Saída em formato JSON (padrão)", reset)
-	fmt.Printf("%s%-20s %s%s%s\n", blue, "  --raw", reset, "Saída formatada como texto simples com todos os campos (util para usar com mapfile/read do bash)", reset)
-	fmt.Printf("%s%-20s %s%s%s\n", blue, "  --pairs", reset, "Usa o formato de saída texto chave='valor' (util para usar com mapfile/read do bash)", reset)
-	fmt.Printf("%s%-20s %s%s%s\n", blue, "  --sep", reset, "Separador dos campos na saída raw (padrão é '=')", reset)
-	fmt.Printf("%s%-20s %s%s%s\n", blue, "  --limit", reset, "Limite de pacotes encontrados", reset)
-	fmt.Printf("%s%-20s %s%s%s\n", blue, "  --verbose", reset, "Liga modo verboso", reset)
-	fmt.Printf("%s%-20s %s%s%s\n", blue, "  --bash", reset, "Mostra exemplo de uso com bash", reset)
-	fmt.Printf("%s%-20s %s%s%s\n", blue, "  --version", reset, "Mostra a versão do aplicativo", reset)
-	fmt.Printf("%s%-20s %s%s%s\n", blue, "  --help", reset, "Este help", reset)
-}
-
-func runSearchPackages() {
-	ch := make(chan Package)
-	var wg sync.WaitGroup
-
-	if searchMode == "search" {
-		for _, term := range searchTerms {
-			wg.Add(1)
-			go searchPackage(term, searchField, limit, &wg, ch)
+	p(gettext("Uso:"))
+	for _, flag := range flagDefs {
+		if flag.Usage != "" && !flag.Hidden {
+			fmt.Printf("%s%-20s %s%s%s%s%s\n", cout.Blue, "  "+strings.Join(flag.Names, ", "), cout.Green, flag.Usage, cout.Cyan, " # "+flag.Desc, cout.Reset)
 		}
-	} else if searchMode == "info" {
-		for _, pkgName := range searchTerms {
-			wg.Add(1)
-			go infoPackage(pkgName, limit, &wg, ch)
+	}
+	p(gettext("    <palavras-chave> são os termos/pacotes de busca"))
+	p(gettext("    <opção> podem ser:"))
+	for _, flag := range flagDefs {
+		if flag.Usage == "" && !flag.Hidden {
+			fmt.Printf("%s%-20s %s%s%s\n", cout.Blue, "  "+strings.Join(flag.Names, ", "), cout.Reset, flag.Desc, cout.Reset)
 		}
 	}
+	fmt.Printf(gettext("    Configuração: /etc/%s.conf e ~/.config/%s.conf (chaves format, sep, limit, by, fields,\n"), _APP_, _APP_)
+	p(gettext("    sort, verbose, aur_url, ...); as opções da linha de comando sempre prevalecem"))
+	p(gettext("    Código de saída 2 indica que a consulta de algum termo ao AUR falhou"))
+	p(gettext("    Código de saída 3 indica ciclos ou dependências não resolvidas no --deps-tree"))
+	fmt.Printf(gettext("    Idioma das mensagens: LC_ALL, LC_MESSAGES ou LANG (catálogos: %s)\n"), strings.Join(i18n.Languages(), ", "))
+}
 
-	go func() {
-		wg.Wait()
-		close(ch)
-	}()
+// Shells aceitos pelo --completion
+var completionShells = []string{"bash", "zsh", "fish"}
 
-	var results []Package
-	for pkg := range ch {
-		if verbose {
-			log.Printf("%s %sGET:%s %02d '%s'%s em %s %s- 200 OK%s\n", _APP_, Green, Yellow, pkg.count, strings.TrimSpace(pkg.Name), Reset, pkg.fullURL, Green, Reset)
-		}
-		results = append(results, pkg)
+// completionScript gera o script de completação do shell a partir de flagDefs
+func completionScript(shell string) string {
+	switch shell {
+	case "zsh":
+		return zshCompletion()
+	case "fish":
+		return fishCompletion()
 	}
+	return bashCompletion()
+}
 
-	if outputFormat == "" {
-		outputFormat = "--json" // Define o formato padrão como json
+func bashCompletion() string {
+	var b strings.Builder
+	var words []string
+	fmt.Fprintf(&b, "# completação do bash para %s, gerada por: %s --completion bash\n", _APP_, _APP_)
+	b.WriteString("_big_search_aur() {\n")
+	b.WriteString("\tlocal cur=${COMP_WORDS[COMP_CWORD]} prev=${COMP_WORDS[COMP_CWORD-1]}\n")
+	b.WriteString("\tcase $prev in\n")
+	for _, flag := range flagDefs {
+		if flag.Hidden {
+			continue
+		}
+		words = append(words, flag.Names...)
+		pattern := strings.Join(flag.Names, "|")
+		switch flag.Arg {
+		case argChoice:
+			fmt.Fprintf(&b, "\t%s)\n\t\tCOMPREPLY=($(compgen -W '%s' -- \"$cur\"))\n\t\treturn ;;\n", pattern, strings.Join(flag.Values, " "))
+		case argList:
+			fmt.Fprintf(&b, "\t%s)\n\t\tlocal prefix=${cur%%\"${cur##*,}\"}\n", pattern)
+			fmt.Fprintf(&b, "\t\tCOMPREPLY=($(compgen -P \"$prefix\" -W '%s' -- \"${cur##*,}\"))\n", strings.Join(flag.Values, " "))
+			b.WriteString("\t\tcompopt -o nospace\n\t\treturn ;;\n")
+		case argDir:
+			fmt.Fprintf(&b, "\t%s)\n\t\tCOMPREPLY=($(compgen -d -- \"$cur\"))\n\t\treturn ;;\n", pattern)
+		case argValue:
+			fmt.Fprintf(&b, "\t%s)\n\t\treturn ;;\n", pattern)
+		}
 	}
+	b.WriteString("\tesac\n")
+	fmt.Fprintf(&b, "\tif [[ $cur == -* ]]; then\n\t\tCOMPREPLY=($(compgen -W '%s' -- \"$cur\"))\n\t\treturn\n\tfi\n", strings.Join(words, " "))
+	b.WriteString("\tlocal IFS=$'\\n'\n")
+	b.WriteString("\tCOMPREPLY=($(\"$1\" --complete-packages \"$cur\" 2>/dev/null))\n")
+	b.WriteString("}\n")
+	fmt.Fprintf(&b, "complete -F _big_search_aur %s\n", _APP_)
+	return b.String()
+}
 
-	if outputFormat == "--json" {
-		jsonData, err := json.MarshalIndent(results, "", "  ")
-		if err != nil {
-			p("Erro ao formatar saída JSON:", err)
-			return
+func zshCompletion() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "#compdef %s\n", _APP_)
+	fmt.Fprintf(&b, "# completação do zsh para %s, gerada por: %s --completion zsh\n\n", _APP_, _APP_)
+	b.WriteString("_big_search_aur_packages() {\n")
+	b.WriteString("\tlocal -a packages\n")
+	b.WriteString("\tpackages=(${(f)\"$(\"${words[1]}\" --complete-packages \"$PREFIX\" 2>/dev/null)\"})\n")
+	b.WriteString("\tcompadd -a packages\n")
+	b.WriteString("}\n\n")
+	b.WriteString("_big_search_aur() {\n")
+	b.WriteString("\t_arguments \\\n")
+	for _, flag := range flagDefs {
+		if flag.Hidden {
+			continue
 		}
-		p(string(jsonData))
-	} else if outputFormat == "--pairs" {
-		separator = "="
-		for _, pkg := range results {
-			fmt.Printf("Name%s'%s' Version%s'%s' Description%s'%s' Maintainer%s'%s' NumVotes%s'%d' Popularity%s'%.2f' URL%s'%s'\n",
-				separator, pkg.Name, separator, pkg.Version, separator, pkg.Description,
-				separator, pkg.Maintainer, separator, pkg.NumVotes, separator, pkg.Popularity,
-				separator, pkg.URL)
+		desc := strings.NewReplacer("'", `'\''`, "[", `\[`, "]", `\]`).Replace(flag.Desc)
+		var action string
+		switch flag.Arg {
+		case argChoice:
+			action = ":" + gettext("valor") + ":(" + strings.Join(flag.Values, " ") + ")"
+		case argList:
+			action = ":" + gettext("campos") + ":_values -s , " + gettext("campo") + " " + strings.Join(flag.Values, " ")
+		case argDir:
+			action = ":" + gettext("diretório") + ":_files -/"
+		case argValue:
+			action = ":" + gettext("valor") + ": "
 		}
-	} else {
-		for _, pkg := range results {
-			echo(pkg.Name + separator +
-				pkg.Version + separator +
-				pkg.Description + separator +
-				pkg.Maintainer + separator +
-				strconv.Itoa(pkg.NumVotes) + separator +
-				strconv.FormatFloat(pkg.Popularity, 'f', -1, 64) + separator +
-				pkg.URL + separator +
-				strconv.Itoa(pkg.count))
+		if len(flag.Names) == 1 {
+			fmt.Fprintf(&b, "\t\t'%s[%s]%s' \\\n", flag.Names[0], desc, action)
+		} else {
+			fmt.Fprintf(&b, "\t\t'(%s)'{%s}'[%s]%s' \\\n", strings.Join(flag.Names, " "), strings.Join(flag.Names, ","), desc, action)
 		}
 	}
+	fmt.Fprintf(&b, "\t\t'*:%s:_big_search_aur_packages'\n", gettext("pacote"))
+	b.WriteString("}\n\n")
+	fmt.Fprintf(&b, "if [ \"$funcstack[1]\" = \"_%s\" ]; then\n", _APP_)
+	b.WriteString("\t_big_search_aur \"$@\"\n")
+	b.WriteString("else\n")
+	fmt.Fprintf(&b, "\tcompdef _big_search_aur %s\n", _APP_)
+	b.WriteString("fi\n")
+	return b.String()
 }
 
-func infoPackage(pkgName string, limit int, wg *sync.WaitGroup, ch chan<- Package) {
-	defer wg.Done()
-
-	count = 0
-	cacheKey := "info|" + pkgName
-	cacheMutex.Lock()
-	if entry, found := cache[cacheKey]; found && time.Since(entry.Timestamp) < cacheTTL {
-		for _, pkg := range entry.Results {
-			ch <- pkg
+func fishCompletion() string {
+	var b strings.Builder
+	quote := func(value string) string {
+		return "'" + strings.NewReplacer(`\`, `\\`, "'", `\'`).Replace(value) + "'"
+	}
+	fmt.Fprintf(&b, "# completação do fish para %s, gerada por: %s --completion fish\n", _APP_, _APP_)
+	b.WriteString("function __big_search_aur_packages\n")
+	b.WriteString("\tset -l cmd (commandline -opc)\n")
+	b.WriteString("\t$cmd[1] --complete-packages (commandline -ct) 2>/dev/null\n")
+	b.WriteString("end\n\n")
+	fmt.Fprintf(&b, "complete -c %s -f\n", _APP_)
+	fmt.Fprintf(&b, "complete -c %s -n 'not string match -q -- \"-*\" (commandline -ct)' -a '(__big_search_aur_packages)'\n", _APP_)
+	for _, flag := range flagDefs {
+		if flag.Hidden {
+			continue
 		}
-		cacheMutex.Unlock()
-		return
+		line := "complete -c " + _APP_
+		for _, name := range flag.Names {
+			line += fishOption(name)
+		}
+		switch flag.Arg {
+		case argChoice:
+			line += " -x -a " + quote(strings.Join(flag.Values, " "))
+		case argList:
+			line += " -x -a " + quote("(__fish_complete_list , 'string split \" \" -- "+strings.Join(flag.Values, " ")+"')")
+		case argDir:
+			line += " -x -a '(__fish_complete_directories (commandline -ct))'"
+		case argValue:
+			line += " -x"
+		}
+		b.WriteString(line + " -d " + quote(flag.Desc) + "\n")
 	}
-	cacheMutex.Unlock()
-
-	queryParams := url.Values{}
-	queryParams.Add("v", "5")
-	queryParams.Add("type", "info")
-	queryParams.Add("arg[]", pkgName)
-
-	// Construir a URL na ordem correta para o tipo info
-	fullURL = fmt.Sprintf("%s?v=%s&type=%s&arg[]=%s", baseURL, queryParams.Get("v"), queryParams.Get("type"), queryParams.Get("arg[]"))
+	return b.String()
+}
 
-	resp, err := http.Get(fullURL)
-	if err != nil {
-		fmt.Printf("Erro ao fazer a requisição para o pacote '%s': %s\n", pkgName, err)
-		return
+// fishOption converte o nome da opção para o complete do fish: -l para as
+// longas, -s para as de uma letra e -o para as antigas, como -Ss
+func fishOption(name string) string {
+	if long, ok := strings.CutPrefix(name, "--"); ok {
+		return " -l " + long
 	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		fmt.Printf("Erro na resposta para o pacote '%s': %s\n", pkgName, resp.Status)
-		return
+	if len(name) == 2 {
+		return " -s " + name[1:]
 	}
+	return " -o " + name[1:]
+}
 
-	data, err := ioutil.ReadAll(resp.Body)
-	if err != nil {
-		fmt.Printf("Erro ao ler a resposta para o pacote '%s': %s\n", pkgName, err)
-		return
-	}
+// Listas packages-meta-v1.json.gz já baixadas (pelo big-aur-packages), usadas
+// para completar os nomes de pacotes sem acessar a rede
+var packagesMetaFiles = []string{
+	filepath.Join(cache.DefaultDir("big-aur-packages"), "packages-meta-v1.json.gz"),
+	"/var/cache/big-aur-packages/packages-meta-v1.json.gz",
+}
 
-	var response map[string]interface{}
-	if err := json.Unmarshal(data, &response); err != nil {
-		fmt.Printf("Erro ao decodificar o JSON para o pacote '%s': %s\n", pkgName, err)
-		return
+// completePackages retorna os nomes de pacotes do AUR que começam com prefix:
+// da primeira lista packages-meta encontrada ou, sem ela, do suggest do AUR
+func completePackages(prefix string) []string {
+	for _, file := range packagesMetaFiles {
+		names, err := metaPackageNames(file, prefix)
+		if err == nil {
+			return names
+		}
+		if verbose && !errors.Is(err, fs.ErrNotExist) {
+			logError(fmt.Sprintf(gettext("Erro ao ler %s: %v"), file, err))
+		}
 	}
+	return suggestPackages(prefix)
+}
 
-	results, ok := response["results"].([]interface{})
-	if !ok {
-		fmt.Printf("Campo 'results' não encontrado na resposta para o pacote '%s'\n", pkgName)
-		return
+// metaPackageNames lê os nomes da lista packages-meta (gzip ou JSON puro),
+// um pacote de cada vez, sem carregar a lista inteira na memória
+func metaPackageNames(file, prefix string) ([]string, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, err
 	}
+	defer f.Close()
 
-	var packages []Package
-	for _, result := range results {
-		pkgData, err := json.Marshal(result)
+	var reader io.Reader = bufio.NewReader(f)
+	if magic, _ := reader.(*bufio.Reader).Peek(2); snapshot.IsGzip(magic) {
+		gz, err := gzip.NewReader(reader)
 		if err != nil {
-			fmt.Printf("Erro ao converter o resultado para o pacote '%s': %s\n", pkgName, err)
-			return
-		}
-		var pkg Package
-		if err := json.Unmarshal(pkgData, &pkg); err != nil {
-			fmt.Printf("Erro ao decodificar o pacote '%s': %s\n", pkgName, err)
-			return
+			return nil, err
 		}
+		defer gz.Close()
+		reader = gz
+	}
 
-		count++
-		if verbose {
-			// Preenche o campo `url` com `fullURL`
-			fullURL = fmt.Sprintf("%s?v=%s&type=%s&arg[]=%s", baseURL, queryParams.Get("v"), queryParams.Get("type"), pkg.Name)
-			pkg.fullURL = fullURL
-			pkg.count = count
+	decoder := json.NewDecoder(reader)
+	if _, err := decoder.Token(); err != nil {
+		return nil, err
+	}
+	var names []string
+	for decoder.More() {
+		var pkg struct{ Name string }
+		if err := decoder.Decode(&pkg); err != nil {
+			return nil, err
 		}
-
-		packages = append(packages, pkg)
-		// Envia todos os pacotes se limit for <= 0
-		if limit <= 0 || count < limit {
-			ch <- pkg
-		} else {
-			break
+		if strings.HasPrefix(pkg.Name, prefix) {
+			names = append(names, pkg.Name)
 		}
 	}
-
-	cacheMutex.Lock()
-	cache[cacheKey] = CacheEntry{Results: packages, Timestamp: time.Now()}
-	cacheMutex.Unlock()
+	sort.Strings(names)
+	return names, nil
 }
 
-func getStringField(data map[string]interface{}, key string) string {
-	if value, ok := data[key]; ok {
-		switch v := value.(type) {
-		case string:
-			return v
-		case float64:
-			return fmt.Sprintf("%f", v)
-		case bool:
-			return fmt.Sprintf("%t", v)
-		default:
-			return fmt.Sprintf("%v", v)
+// suggestPackages consulta o /rpc?type=suggest do AUR, que devolve até 20
+// nomes. Como roda a cada Tab, espera pouco e não repete as falhas.
+func suggestPackages(prefix string) []string {
+	if prefix == "" {
+		return nil
+	}
+	key := cache.Key(baseURL, "suggest", prefix, "")
+	var names []string
+	if !noCache && !refreshCache {
+		if entry, found := diskCache.Get(key); found && json.Unmarshal(entry.Data, &names) == nil {
+			return names
 		}
 	}
-	return ""
+
+	suggestURL := fmt.Sprintf("%s?v=5&type=suggest&arg=%s", baseURL, url.QueryEscape(prefix))
+	data, err := aurGetWith(suggestURL, getOptions{timeout: 3 * time.Second, retries: 0})
+	if err != nil || json.Unmarshal(data, &names) != nil {
+		return nil
+	}
+	if !noCache {
+		diskCache.Put(key, cache.Entry{Endpoint: baseURL, Mode: "suggest", Term: prefix, Data: data})
+	}
+	return names
+}
+
+func runSearchPackages() {
+	ch := make(chan Package)
+	var wg sync.WaitGroup
+
+	if searchMode == "search" && allTerms {
+		wg.Add(1)
+		go searchAllTerms(searchTerms, searchField, &wg, ch)
+	} else if searchMode == "search" {
+		for _, term := range searchTerms {
+			wg.Add(1)
+			go searchPackage(term, searchField, &wg, ch)
+		}
+	} else if searchMode == "info" {
+		wg.Add(1)
+		go infoPackage(searchTerms, &wg, ch)
+	} else if searchMode == "rdeps" {
+		wg.Add(1)
+		go rdepsPackage(searchTerms, &wg, ch)
+	}
+
+	go func() {
+		wg.Wait()
+		close(ch)
+	}()
+
+	if outputFormat == "" {
+		outputFormat = "--json" // Define o formato padrão como json
+	}
+
+	// Sem --sort, o --ndjson mostra cada pacote assim que ele chega do canal
+	streaming := outputFormat == "--ndjson" && sortMode == ""
+
+	var results []Package
+	seen := make(map[string]bool)
+	for pkg := range ch {
+		// O mesmo pacote pode vir de mais de um termo
+		if seen[pkg.Name] {
+			continue
+		}
+		seen[pkg.Name] = true
+		if streaming {
+			if limit > 0 && len(results) >= limit {
+				continue // esvazia o canal para liberar os produtores
+			}
+			count = len(results) + 1
+			pkg.count = count
+			results = append(results, pkg)
+			p(string(packageJSON(pkg)))
+			continue
+		}
+		results = append(results, pkg)
+	}
+	if streaming {
+		return
+	}
+
+	// Ordena antes de aplicar o --limit, para que o usuário receba sempre os
+	// primeiros N pacotes da ordenação escolhida
+	if sortMode != "" {
+		sortPackages(results, sortMode, searchTerms, reverseSort)
+	}
+	if limit > 0 && len(results) > limit {
+		results = results[:limit]
+	}
+	for i := range results {
+		count = i + 1
+		results[i].count = count
+		if verbose {
+			pkg := results[i]
+			log.Printf(gettext("%s %sGET:%s %02d '%s'%s em %s %s- 200 OK%s\n"), _APP_, cerr.Green, cerr.Yellow, pkg.count, strings.TrimSpace(pkg.Name), cerr.Reset, pkg.fullURL, cerr.Green, cerr.Reset)
+		}
+	}
+
+	if pick {
+		pickPackages(results)
+		return
+	}
+	printResults(results)
+}
+
+var sortModes = []string{"votes", "popularity", "name", "modified", "relevance"}
+
+func isSortMode(mode string) bool {
+	for _, m := range sortModes {
+		if m == mode {
+			return true
+		}
+	}
+	return false
+}
+
+// sortPackages ordena os pacotes pelo modo do --sort. Votos, popularidade e
+// data de modificação vêm do maior para o menor; nome em ordem alfabética;
+// relevance coloca primeiro os nomes idênticos a um termo, depois os que
+// começam pelo termo e por fim os que só coincidem na descrição.
+func sortPackages(packages []Package, mode string, terms []string, reverse bool) {
+	less := func(a, b Package) bool {
+		switch mode {
+		case "votes":
+			if a.NumVotes != b.NumVotes {
+				return a.NumVotes > b.NumVotes
+			}
+		case "popularity":
+			if a.Popularity != b.Popularity {
+				return a.Popularity > b.Popularity
+			}
+		case "modified":
+			if a.LastModified != b.LastModified {
+				return a.LastModified > b.LastModified
+			}
+		case "relevance":
+			ra, rb := relevance(a, terms), relevance(b, terms)
+			if ra != rb {
+				return ra < rb
+			}
+			if a.Popularity != b.Popularity {
+				return a.Popularity > b.Popularity
+			}
+		}
+		return a.Name < b.Name
+	}
+	sort.SliceStable(packages, func(i, j int) bool {
+		if reverse {
+			return less(packages[j], packages[i])
+		}
+		return less(packages[i], packages[j])
+	})
+}
+
+// relevance retorna a classe do pacote (menor = mais relevante) em relação
+// ao melhor dos termos buscados
+func relevance(pkg Package, terms []string) int {
+	name := strings.ToLower(pkg.Name)
+	description := strings.ToLower(pkg.Description)
+	best := 4
+	for _, term := range terms {
+		term = strings.ToLower(term)
+		rank := 4
+		switch {
+		case name == term:
+			rank = 0
+		case strings.HasPrefix(name, term):
+			rank = 1
+		case strings.Contains(name, term):
+			rank = 2
+		case strings.Contains(description, term):
+			rank = 3
+		}
+		if rank < best {
+			best = rank
+		}
+	}
+	return best
+}
+
+// pickPackages abre o seletor interativo e mostra na saída padrão os nomes
+// escolhidos, um por linha
+func pickPackages(results []Package) {
+	if len(results) == 0 {
+		msgError(gettext("Erro: nenhum pacote encontrado"))
+		return
+	}
+	names := make([]string, len(results))
+	labels := make([]string, len(results))
+	for i, pkg := range results {
+		names[i] = pkg.Name
+		labels[i] = fmt.Sprintf(gettext("%s %s (%d votos)"), pkg.Name, pkg.Version, pkg.NumVotes)
+	}
+
+	// Os detalhes de todos os pacotes vêm em uma só leva de requisições info
+	var details map[string]Package
+	var detailsErr error
+	var detailsOnce sync.Once
+	preview := func(i int) []string {
+		detailsOnce.Do(func() {
+			details = fetchInfo(names, func(batch []string, err error) {
+				if detailsErr == nil {
+					detailsErr = err
+				}
+			})
+		})
+		pkg, ok := details[names[i]]
+		if !ok {
+			pkg = results[i]
+		}
+		lines := previewLines(pkg)
+		if detailsErr != nil {
+			lines = append(lines, "", gettext("Erro ao buscar detalhes: ")+detailsErr.Error())
+		}
+		return lines
+	}
+
+	list := &picker.Picker{Items: labels, Preview: preview, Prompt: "> ", Multi: true}
+	chosen, err := list.Run()
+	if err != nil {
+		msgError(gettext("Erro: ") + err.Error())
+		os.Exit(exitPickCancelled)
+	}
+	for _, i := range chosen {
+		p(names[i])
+	}
+}
+
+// previewLines monta o painel de pré-visualização do --pick
+func previewLines(pkg Package) []string {
+	lines := []string{
+		"Name           : " + pkg.Name,
+		"Version        : " + pkg.Version,
+		"Description    : " + pkg.Description,
+		"Maintainer     : " + pkg.Maintainer,
+		"Votes          : " + strconv.Itoa(pkg.NumVotes),
+		"Popularity     : " + strconv.FormatFloat(pkg.Popularity, 'f', 2, 64),
+		"URL            : " + pkg.URL,
+		"AUR URL        : https://aur.archlinux.org/packages/" + pkg.Name,
+	}
+	if pkg.OutOfDate != nil {
+		lines = append(lines, "Out-of-date    : "+time.Unix(*pkg.OutOfDate, 0).Format(time.DateOnly))
+	}
+	if pkg.LastModified > 0 {
+		lines = append(lines, "Last Modified  : "+time.Unix(pkg.LastModified, 0).Format(time.DateTime))
+	}
+	for _, list := range []struct {
+		label  string
+		values []string
+	}{
+		{"License", pkg.License},
+		{"Keywords", pkg.Keywords},
+		{"Provides", pkg.Provides},
+		{"Conflicts", pkg.Conflicts},
+		{"Depends", pkg.Depends},
+		{"MakeDepends", pkg.MakeDepends},
+		{"OptDepends", pkg.OptDepends},
+		{"CheckDepends", pkg.CheckDepends},
+	} {
+		if len(list.values) == 0 {
+			continue
+		}
+		lines = append(lines, fmt.Sprintf("%-15s: %s", list.label, list.values[0]))
+		for _, value := range list.values[1:] {
+			lines = append(lines, strings.Repeat(" ", 17)+value)
+		}
+	}
+	return lines
+}
+
+// resultEnvelope é o objeto da saída --json
+type resultEnvelope struct {
+	Query     queryInfo         `json:"query"`
+	Results   []json.RawMessage `json:"results"`
+	Errors    []termError       `json:"errors"`
+	NotFound  []string          `json:"not_found,omitempty"` // nomes do -Si que não existem no AUR
+	ElapsedMS int64             `json:"elapsed_ms"`
+}
+
+// queryInfo descreve a consulta que gerou os resultados
+type queryInfo struct {
+	Mode     string   `json:"mode"`
+	Terms    []string `json:"terms"`
+	By       string   `json:"by,omitempty"`
+	AllTerms bool     `json:"all_terms,omitempty"`
+	Sort     string   `json:"sort,omitempty"`
+	Reverse  bool     `json:"reverse,omitempty"`
+	Limit    int      `json:"limit,omitempty"`
+}
+
+// packageJSON serializa o pacote em uma linha, respeitando o --fields
+func packageJSON(pkg Package) json.RawMessage {
+	if len(selectedFields) > 0 {
+		return fieldsJSON(pkg, selectedFields)
+	}
+	data, err := json.Marshal(pkg)
+	if err != nil {
+		return json.RawMessage("null")
+	}
+	return data
+}
+
+// printResults mostra os pacotes no formato escolhido (--json, --ndjson, --pairs ou --raw)
+func printResults(results []Package) {
+	if outputFormat == "--json" {
+		objects := make([]json.RawMessage, 0, len(results))
+		for _, pkg := range results {
+			objects = append(objects, packageJSON(pkg))
+		}
+		errorList := failures
+		if errorList == nil {
+			errorList = []termError{}
+		}
+		jsonData, err := json.MarshalIndent(resultEnvelope{
+			Query: queryInfo{
+				Mode:     searchMode,
+				Terms:    searchTerms,
+				By:       searchField,
+				AllTerms: allTerms,
+				Sort:     sortMode,
+				Reverse:  reverseSort,
+				Limit:    max(limit, 0),
+			},
+			Results:   objects,
+			Errors:    errorList,
+			NotFound:  notFoundNames,
+			ElapsedMS: time.Since(startTime).Milliseconds(),
+		}, "", "  ")
+		if err != nil {
+			msgError(gettext("Erro ao formatar saída JSON: ") + err.Error())
+			return
+		}
+		p(string(jsonData))
+	} else if outputFormat == "--ndjson" {
+		for _, pkg := range results {
+			p(string(packageJSON(pkg)))
+		}
+	} else if outputFormat == "--pairs" {
+		separator = "="
+		for _, pkg := range results {
+			fields := outputFields(false)
+			pairs := make([]string, 0, len(fields))
+			for _, field := range fields {
+				value := fieldString(pkg, field)
+				if field == "Popularity" {
+					value = fmt.Sprintf("%.2f", pkg.Popularity)
+				}
+				// Uma linha por pacote: quebras de linha viram espaço
+				value = strings.ReplaceAll(value, "\n", " ")
+				pairs = append(pairs, field+separator+singleQuote(value))
+			}
+			echo(strings.Join(pairs, " "))
+		}
+	} else if outputFormat == "--shell" {
+		for _, pkg := range results {
+			fields := outputFields(false)
+			items := make([]string, 0, len(fields))
+			for _, field := range fields {
+				items = append(items, "["+field+"]="+shellQuote(fieldString(pkg, field)))
+			}
+			echo("declare -A pkg=(" + strings.Join(items, " ") + ")")
+		}
+	} else if outputFormat == "--null" {
+		var sb strings.Builder
+		for _, pkg := range results {
+			for _, field := range outputFields(false) {
+				sb.WriteString(strings.ReplaceAll(fieldString(pkg, field), "\x00", ""))
+				sb.WriteByte(0)
+			}
+		}
+		fmt.Print(sb.String())
+	} else {
+		for _, pkg := range results {
+			fields := outputFields(true)
+			values := make([]string, 0, len(fields))
+			for _, field := range fields {
+				values = append(values, escapeRaw(fieldString(pkg, field), separator))
+			}
+			echo(strings.Join(values, separator))
+		}
+	}
+}
+
+// outputFields retorna os campos das saídas de texto: os do --fields ou
+// todos. Na saída --raw o contador fica na 8ª coluna, como nas versões
+// anteriores.
+func outputFields(raw bool) []string {
+	if len(selectedFields) > 0 {
+		return selectedFields
+	}
+	fields := allFields
+	if searchMode == "rdeps" {
+		fields = append(append([]string{}, allFields...), "Relations")
+	}
+	if raw {
+		return append(append(append([]string{}, fields[:7]...), "count"), fields[7:]...)
+	}
+	return fields
+}
+
+// escapeRaw protege o separador dentro do campo com '\', como o read do
+// bash (sem -r) espera; quebras de linha viram espaço
+func escapeRaw(value, sep string) string {
+	if sep == "" {
+		return value
+	}
+	value = strings.ReplaceAll(value, "\\", "\\\\")
+	value = strings.ReplaceAll(value, sep, "\\"+sep)
+	return strings.ReplaceAll(value, "\n", " ")
+}
+
+// singleQuote coloca o valor entre aspas simples, trocando cada ' por '\''
+func singleQuote(value string) string {
+	return "'" + strings.ReplaceAll(value, "'", `'\''`) + "'"
+}
+
+// shellQuote gera um literal seguro para o eval do bash: aspas simples em
+// geral e $'...' quando há caracteres de controle, para manter tudo em uma
+// linha
+func shellQuote(value string) string {
+	hasControl := strings.IndexFunc(value, func(r rune) bool { return r < 0x20 || r == 0x7f }) >= 0
+	if !hasControl {
+		return singleQuote(value)
+	}
+	var sb strings.Builder
+	sb.WriteString("$'")
+	for _, r := range value {
+		switch r {
+		case '\\', '\'':
+			sb.WriteRune('\\')
+			sb.WriteRune(r)
+		case '\n':
+			sb.WriteString(`\n`)
+		case '\t':
+			sb.WriteString(`\t`)
+		case '\r':
+			sb.WriteString(`\r`)
+		case 0:
+			// bash não guarda NUL em variáveis
+		default:
+			if r < 0x20 || r == 0x7f {
+				fmt.Fprintf(&sb, `\x%02x`, r)
+			} else {
+				sb.WriteRune(r)
+			}
+		}
+	}
+	sb.WriteString("'")
+	return sb.String()
+}
+
+// parseFields valida a lista do --fields, aceitando os nomes sem diferenciar
+// maiúsculas de minúsculas
+func parseFields(list string) ([]string, error) {
+	var fields []string
+	for _, name := range strings.Split(list, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		found := false
+		for _, field := range append(allFields, "Relations") {
+			if strings.EqualFold(name, field) {
+				fields = append(fields, field)
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf(gettext("campo desconhecido '%s' (campos válidos: %s)"), name, strings.Join(allFields, ","))
+		}
+	}
+	if len(fields) == 0 {
+		return nil, errors.New(gettext("--fields requer ao menos um campo"))
+	}
+	return fields, nil
+}
+
+// fieldValue retorna o valor do campo pelo nome usado no JSON do AUR
+func fieldValue(pkg Package, field string) interface{} {
+	if field == "count" {
+		return pkg.count
+	}
+	return reflect.ValueOf(pkg).FieldByName(field).Interface()
+}
+
+// fieldString converte o campo em texto para as saídas --raw e --pairs; as
+// listas são separadas por espaço e OutOfDate nulo vira string vazia
+func fieldString(pkg Package, field string) string {
+	switch v := fieldValue(pkg, field).(type) {
+	case string:
+		return v
+	case int:
+		return strconv.Itoa(v)
+	case int64:
+		return strconv.FormatInt(v, 10)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case *int64:
+		if v == nil {
+			return ""
+		}
+		return strconv.FormatInt(*v, 10)
+	case []string:
+		return strings.Join(v, " ")
+	case []Relation:
+		relations := make([]string, 0, len(v))
+		for _, relation := range v {
+			relations = append(relations, relation.Kind+":"+relation.Dependency)
+		}
+		return strings.Join(relations, " ")
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// fieldsJSON gera um objeto JSON apenas com os campos pedidos, na ordem pedida
+func fieldsJSON(pkg Package, fields []string) json.RawMessage {
+	var sb strings.Builder
+	sb.WriteString("{")
+	for i, field := range fields {
+		if i > 0 {
+			sb.WriteString(",")
+		}
+		key, _ := json.Marshal(field)
+		value, err := json.Marshal(fieldValue(pkg, field))
+		if err != nil {
+			value = []byte("null")
+		}
+		sb.Write(key)
+		sb.WriteString(":")
+		sb.Write(value)
+	}
+	sb.WriteString("}")
+	return json.RawMessage(sb.String())
+}
+
+// infoPackage consulta as informações dos pacotes em lotes (vários arg[] por
+// requisição) e envia os resultados na mesma ordem da linha de comando
+func infoPackage(pkgNames []string, wg *sync.WaitGroup, ch chan<- Package) {
+	defer wg.Done()
+
+	found := infoDetails(pkgNames)
+
+	var notFound []string
+	done := make(map[string]bool)
+	for _, pkgName := range pkgNames {
+		if done[pkgName] {
+			continue // nome repetido na linha de comando sai uma vez só
+		}
+		done[pkgName] = true
+		pkg, ok := found[pkgName]
+		if !ok {
+			notFound = append(notFound, pkgName)
+			continue
+		}
+		if verbose {
+			pkg.fullURL = infoURL([]string{pkg.Name})
+		}
+		ch <- pkg
+	}
+
+	if len(notFound) > 0 {
+		logError(gettext("Pacote(s) não encontrado(s) no AUR: "), strings.Join(notFound, " "))
+	}
+	notFoundNames = notFound
+}
+
+// rdepsPackage procura as dependências reversas dos pacotes: as quatro
+// buscas by (depends, makedepends, optdepends e checkdepends) de cada nome
+// rodam em paralelo e os resultados são juntados, um pacote por nome, com o
+// campo Relations dizendo como cada um depende do pacote pedido
+func rdepsPackage(pkgNames []string, wg *sync.WaitGroup, ch chan<- Package) {
+	defer wg.Done()
+
+	type match struct {
+		name, kind string
+		packages   []Package
+	}
+	matches := make(chan match)
+	var searchWg sync.WaitGroup
+	for _, pkgName := range pkgNames {
+		for _, kind := range relationKinds {
+			searchWg.Add(1)
+			go func(pkgName, kind string) {
+				defer searchWg.Done()
+				packages, err := searchTerm(pkgName, kind)
+				if err != nil {
+					recordFailure(pkgName+" ("+kind+")", err)
+					return
+				}
+				matches <- match{pkgName, kind, packages}
+			}(pkgName, kind)
+		}
+	}
+	go func() {
+		searchWg.Wait()
+		close(matches)
+	}()
+
+	// relations[pacote][tipo] = nomes pedidos que o pacote usa nesse tipo
+	relations := make(map[string]map[string][]string)
+	found := make(map[string]Package)
+	for m := range matches {
+		for _, pkg := range m.packages {
+			if relations[pkg.Name] == nil {
+				relations[pkg.Name] = make(map[string][]string)
+				found[pkg.Name] = pkg
+			}
+			relations[pkg.Name][m.kind] = append(relations[pkg.Name][m.kind], m.name)
+		}
+	}
+
+	// A busca não traz as listas de dependências; o info traz a entrada exata
+	resultNames := make([]string, 0, len(found))
+	for name := range found {
+		resultNames = append(resultNames, name)
+	}
+	sort.Strings(resultNames)
+	details := infoDetails(resultNames)
+
+	for _, name := range resultNames {
+		pkg, ok := details[name]
+		if !ok {
+			pkg = found[name]
+		}
+		for _, kind := range relationKinds {
+			targets := relations[name][kind]
+			sort.Strings(targets)
+			for _, target := range targets {
+				pkg.Relations = append(pkg.Relations, Relation{Kind: kind, Dependency: matchingDependency(pkg, kind, target)})
+			}
+		}
+		ch <- pkg
+	}
+}
+
+// matchingDependency retorna a entrada da lista do tipo kind que se refere
+// ao pacote target (ex: "glibc>=2.38" ou "python: para os scripts"); sem as
+// listas do info, devolve o próprio nome
+func matchingDependency(pkg Package, kind, target string) string {
+	var deps []string
+	switch kind {
+	case "depends":
+		deps = pkg.Depends
+	case "makedepends":
+		deps = pkg.MakeDepends
+	case "optdepends":
+		deps = pkg.OptDepends
+	case "checkdepends":
+		deps = pkg.CheckDepends
+	}
+	for _, dep := range deps {
+		if dependencyName(dep) == target {
+			return dep
+		}
+	}
+	return target
+}
+
+// infoDetails busca as informações dos pacotes, usando o cache em disco e
+// agrupando os nomes restantes em lotes consultados em paralelo
+func infoDetails(pkgNames []string) map[string]Package {
+	return fetchInfo(pkgNames, func(batch []string, err error) {
+		recordFailure(strings.Join(batch, " "), err)
+	})
+}
+
+// fetchInfo é o infoDetails com o tratamento de erro dos lotes a cargo de
+// quem chama (o --pick não pode escrever no terminal enquanto desenha).
+// onError é chamado com o mutex travado, uma chamada por vez.
+func fetchInfo(pkgNames []string, onError func(batch []string, err error)) map[string]Package {
+	found := make(map[string]Package)
+	var mutex sync.Mutex
+	var pending []string
+	seen := make(map[string]bool)
+	for _, pkgName := range pkgNames {
+		if seen[pkgName] {
+			continue
+		}
+		seen[pkgName] = true
+		if packages, ok := cacheGet("info", pkgName, ""); ok {
+			for _, pkg := range packages {
+				found[pkg.Name] = pkg
+			}
+			continue
+		}
+		pending = append(pending, pkgName)
+	}
+
+	var batchWg sync.WaitGroup
+	for _, batch := range infoBatches(pending) {
+		batchWg.Add(1)
+		go func(batch []string) {
+			defer batchWg.Done()
+			packages, err := infoBatch(batch)
+			mutex.Lock()
+			defer mutex.Unlock()
+			if err != nil {
+				onError(batch, err)
+				return
+			}
+			for _, pkg := range packages {
+				found[pkg.Name] = pkg
+				cachePut("info", pkg.Name, "", []Package{pkg})
+			}
+		}(batch)
+	}
+	batchWg.Wait()
+	return found
+}
+
+// infoURL monta a URL do tipo info com um arg[] para cada pacote
+func infoURL(pkgNames []string) string {
+	var sb strings.Builder
+	sb.WriteString(baseURL + "?v=5&type=info")
+	for _, pkgName := range pkgNames {
+		sb.WriteString("&arg[]=" + url.QueryEscape(pkgName))
+	}
+	return sb.String()
+}
+
+// infoBatches divide os nomes em lotes cuja URL não ultrapassa maxURLLength
+func infoBatches(pkgNames []string) [][]string {
+	var batches [][]string
+	var batch []string
+	length := len(infoURL(nil))
+	for _, pkgName := range pkgNames {
+		argLength := len("&arg[]=" + url.QueryEscape(pkgName))
+		if len(batch) > 0 && length+argLength > maxURLLength {
+			batches = append(batches, batch)
+			batch = nil
+			length = len(infoURL(nil))
+		}
+		batch = append(batch, pkgName)
+		length += argLength
+	}
+	if len(batch) > 0 {
+		batches = append(batches, batch)
+	}
+	return batches
+}
+
+// infoBatch faz uma única requisição info para todos os nomes do lote
+func infoBatch(pkgNames []string) ([]Package, error) {
+	fullURL := infoURL(pkgNames)
+	if verbose {
+		log.Printf(gettext("%s %sGET:%s %d pacote(s)%s em %s\n"), _APP_, cerr.Green, cerr.Yellow, len(pkgNames), cerr.Reset, fullURL)
+	}
+
+	return rpcResults(fullURL)
+}
+
+// upgradeInfo é um pacote estrangeiro na saída do -Qua
+type upgradeInfo struct {
+	Name         string `json:"name"`
+	LocalVersion string `json:"local_version"`
+	AURVersion   string `json:"aur_version,omitempty"`
+	Maintainer   string `json:"maintainer,omitempty"`
+	OutOfDate    *int64 `json:"out_of_date,omitempty"`
+}
+
+// upgradeReport é o resultado do -Qua. Um pacote pode aparecer em mais de
+// uma lista (por exemplo, com atualização e sem mantenedor).
+type upgradeReport struct {
+	Upgradable []upgradeInfo `json:"upgradable"`
+	Missing    []upgradeInfo `json:"missing"`  // instalados, mas não existem no AUR
+	Orphaned   []upgradeInfo `json:"orphaned"` // sem mantenedor no AUR
+	OutOfDate  []upgradeInfo `json:"out_of_date"`
+	Errors     []termError   `json:"errors"`
+	ElapsedMS  int64         `json:"elapsed_ms"`
+}
+
+// runUpgradeCheck compara os pacotes estrangeiros instalados (os que não
+// estão em nenhum repositório, como no pacman -Qm) com as versões do AUR
+func runUpgradeCheck() {
+	foreign, err := alpm.ForeignPackages(dbPath)
+	if err != nil {
+		msgError(fmt.Sprintf(gettext("Erro ao ler o banco de dados do pacman em %s: %v"), dbPath, err))
+		os.Exit(1)
+	}
+	pkgNames := make([]string, 0, len(foreign))
+	for _, pkg := range foreign {
+		pkgNames = append(pkgNames, pkg.Name)
+	}
+	printUpgradeReport(checkUpgrades(foreign, infoDetails(pkgNames)))
+}
+
+// checkUpgrades classifica os pacotes instalados conforme o que o AUR retornou
+func checkUpgrades(installed []alpm.Package, aur map[string]Package) upgradeReport {
+	report := upgradeReport{
+		Upgradable: []upgradeInfo{},
+		Missing:    []upgradeInfo{},
+		Orphaned:   []upgradeInfo{},
+		OutOfDate:  []upgradeInfo{},
+	}
+	for _, local := range installed {
+		info := upgradeInfo{Name: local.Name, LocalVersion: local.Version}
+		pkg, ok := aur[local.Name]
+		if !ok {
+			// Um lote que falhou não quer dizer que o pacote sumiu do AUR
+			if !failedName(local.Name) {
+				report.Missing = append(report.Missing, info)
+			}
+			continue
+		}
+		info.AURVersion = pkg.Version
+		info.Maintainer = pkg.Maintainer
+		info.OutOfDate = pkg.OutOfDate
+		if vercmp.Compare(pkg.Version, local.Version) > 0 {
+			report.Upgradable = append(report.Upgradable, info)
+		}
+		if pkg.Maintainer == "" {
+			report.Orphaned = append(report.Orphaned, info)
+		}
+		if pkg.OutOfDate != nil {
+			report.OutOfDate = append(report.OutOfDate, info)
+		}
+	}
+	return report
+}
+
+// failedName informa se o nome fazia parte de um lote info que falhou
+func failedName(name string) bool {
+	failuresMutex.Lock()
+	defer failuresMutex.Unlock()
+	for _, failure := range failures {
+		for _, term := range strings.Fields(failure.Term) {
+			if term == name {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// printUpgradeReport mostra o -Qua em JSON (padrão) ou em texto, no formato
+// "nome versão-local -> versão-aur" do pacman -Qu
+func printUpgradeReport(report upgradeReport) {
+	if outputFormat == "" || outputFormat == "--json" {
+		report.Errors = failures
+		if report.Errors == nil {
+			report.Errors = []termError{}
+		}
+		report.ElapsedMS = time.Since(startTime).Milliseconds()
+		jsonData, err := json.MarshalIndent(report, "", "  ")
+		if err != nil {
+			msgError(gettext("Erro ao formatar saída JSON: ") + err.Error())
+			return
+		}
+		p(string(jsonData))
+		return
+	}
+
+	for _, info := range report.Upgradable {
+		fmt.Printf("%s %s%s%s -> %s%s%s\n", info.Name, cout.Red, info.LocalVersion, cout.Reset, cout.Green, info.AURVersion, cout.Reset)
+	}
+	sections := []struct {
+		title    string
+		packages []upgradeInfo
+	}{
+		{gettext("Não encontrados no AUR"), report.Missing},
+		{gettext("Órfãos (sem mantenedor)"), report.Orphaned},
+		{gettext("Marcados como desatualizados"), report.OutOfDate},
+	}
+	for _, section := range sections {
+		if len(section.packages) == 0 {
+			continue
+		}
+		fmt.Printf("%s:: %s%s\n", cout.Yellow, section.title, cout.Reset)
+		for _, info := range section.packages {
+			line := info.Name + " " + info.LocalVersion
+			if info.OutOfDate != nil {
+				line += fmt.Sprintf(gettext(" (desde %s)"), time.Unix(*info.OutOfDate, 0).Format("2006-01-02"))
+			}
+			p("   " + line)
+		}
+	}
+}
+
+// snapshotResult é o resultado do -G para um PackageBase
+type snapshotResult struct {
+	Name        string `json:"name"`
+	PackageBase string `json:"package_base"`
+	Version     string `json:"version"`
+	Dir         string `json:"dir"`
+	Status      string `json:"status"` // downloaded, up-to-date ou failed
+	Error       string `json:"error,omitempty"`
+}
+
+// runGetPkgbuild baixa os snapshots dos pacotes pedidos com -G. Pacotes do
+// mesmo PackageBase (split packages) geram um único download.
+func runGetPkgbuild() {
+	details := infoDetails(searchTerms)
+
+	var results []*snapshotResult
+	bases := make(map[string]bool)
+	var wg sync.WaitGroup
+	for _, name := range searchTerms {
+		pkg, ok := details[name]
+		if !ok {
+			if !failedName(name) {
+				notFoundNames = append(notFoundNames, name)
+			}
+			continue
+		}
+		if bases[pkg.PackageBase] {
+			continue
+		}
+		bases[pkg.PackageBase] = true
+		result := &snapshotResult{Name: pkg.Name, PackageBase: pkg.PackageBase, Version: pkg.Version, Dir: pkg.PackageBase}
+		results = append(results, result)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			status, err := getSnapshot(pkg, result.Dir)
+			result.Status = status
+			if err != nil {
+				result.Error = err.Error()
+				recordFailure(pkg.PackageBase, err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if len(notFoundNames) > 0 {
+		logError(gettext("Pacote(s) não encontrado(s) no AUR: "), strings.Join(notFoundNames, " "))
+	}
+	if outputFormat == "--json" {
+		jsonData, err := json.MarshalIndent(results, "", "  ")
+		if err != nil {
+			msgError(gettext("Erro ao formatar saída JSON: ") + err.Error())
+			return
+		}
+		p(string(jsonData))
+		return
+	}
+	for _, result := range results {
+		switch result.Status {
+		case "downloaded":
+			fmt.Printf("%s:: %s%s %s -> ./%s\n", cout.Green, result.PackageBase, cout.Reset, result.Version, result.Dir)
+		case "up-to-date":
+			fmt.Printf(gettext("%s:: %s%s %s já está atualizado em ./%s\n"), cout.Cyan, result.PackageBase, cout.Reset, result.Version, result.Dir)
+		}
+	}
+}
+
+// getSnapshot baixa e extrai o snapshot em dir, a menos que dir já tenha
+// um .SRCINFO com a mesma versão do AUR (ou --force)
+func getSnapshot(pkg Package, dir string) (string, error) {
+	if !force {
+		info, err := srcinfo.ParseFile(filepath.Join(dir, ".SRCINFO"))
+		if err == nil && info.Version() == pkg.Version {
+			return "up-to-date", nil
+		}
+		if os.IsNotExist(err) {
+			if _, statErr := os.Stat(dir); statErr == nil {
+				return "failed", fmt.Errorf(gettext("./%s já existe e não tem .SRCINFO (use --force para sobrescrever)"), dir)
+			}
+		}
+	}
+
+	snapshotURL, err := aurWebURL(pkg.URLPath)
+	if err != nil {
+		return "failed", err
+	}
+	if verbose {
+		log.Printf("%s %sGET:%s %s%s\n", _APP_, cerr.Green, cerr.Yellow, snapshotURL, cerr.Reset)
+	}
+	data, err := aurGet(snapshotURL)
+	if err != nil {
+		return "failed", err
+	}
+	if err := snapshot.Extract(data, dir, pkg.PackageBase); err != nil {
+		return "failed", err
+	}
+	return "downloaded", nil
+}
+
+// aurWebURL monta a URL de um caminho do site do AUR (URLPath do snapshot,
+// /cgit/...) no mesmo servidor do AUR RPC em uso (--aur-url). Num mirror
+// servido abaixo de um prefixo (https://host/aur/rpc) o caminho fica abaixo
+// do mesmo prefixo (https://host/aur/cgit/...).
+func aurWebURL(urlPath string) (string, error) {
+	if urlPath == "" {
+		return "", errors.New(gettext("o AUR não informou o URLPath do snapshot"))
+	}
+	base, err := url.Parse(baseURL)
+	if err != nil {
+		return "", err
+	}
+	ref, err := url.Parse(urlPath)
+	if err != nil {
+		return "", err
+	}
+	if ref.IsAbs() || ref.Host != "" {
+		return ref.String(), nil
+	}
+	prefix := strings.TrimSuffix(strings.TrimSuffix(base.Path, "/"), "/rpc")
+	base.Path = prefix + "/" + strings.TrimPrefix(ref.Path, "/")
+	base.RawPath = ""
+	base.RawQuery = ref.RawQuery
+	base.Fragment = ""
+	return base.String(), nil
+}
+
+// srcinfoView é a saída do --srcinfo: os valores da pkgbase, cada pacote
+// com os valores efetivos e cada source com os seus checksums
+type srcinfoView struct {
+	File          string            `json:"file"` // arquivo lido ou URL baixada
+	Pkgbase       string            `json:"pkgbase"`
+	Version       string            `json:"version"`
+	Pkgver        string            `json:"pkgver"`
+	Pkgrel        string            `json:"pkgrel"`
+	Epoch         string            `json:"epoch,omitempty"`
+	MakeDepends   []srcinfo.Value   `json:"makedepends,omitempty"`
+	CheckDepends  []srcinfo.Value   `json:"checkdepends,omitempty"`
+	ValidPGPKeys  []string          `json:"validpgpkeys,omitempty"`
+	Sources       []srcinfo.Source  `json:"sources"`
+	SkipChecksums bool              `json:"skip_checksums"` // algum source com checksum SKIP
+	Packages      []srcinfo.Package `json:"packages"`
+}
+
+// runSrcinfo mostra o .SRCINFO de cada argumento: um arquivo, um diretório
+// com .SRCINFO (como os criados pelo -G) ou o nome de um pacote do AUR
+func runSrcinfo() {
+	var views []srcinfoView
+	var remote []string
+	local := make(map[string]srcinfoView)
+	localFailed := make(map[string]bool)
+	for _, term := range searchTerms {
+		filePath := term
+		if stat, err := os.Stat(term); err != nil {
+			remote = append(remote, term)
+			continue
+		} else if stat.IsDir() {
+			filePath = filepath.Join(term, ".SRCINFO")
+		}
+		info, err := srcinfo.ParseFile(filePath)
+		if err != nil {
+			recordFailure(term, err)
+			localFailed[term] = true
+			continue
+		}
+		local[term] = newSrcinfoView(filePath, info)
+	}
+
+	details := infoDetails(remote)
+	for _, term := range searchTerms {
+		if view, ok := local[term]; ok {
+			views = append(views, view)
+			continue
+		}
+		if localFailed[term] {
+			continue
+		}
+		pkg, ok := details[term]
+		if !ok {
+			if !failedName(term) {
+				notFoundNames = append(notFoundNames, term)
+			}
+			continue
+		}
+		plainURL, err := aurWebURL("/cgit/aur.git/plain/.SRCINFO?h=" + url.QueryEscape(pkg.PackageBase))
+		if err != nil {
+			recordFailure(term, err)
+			continue
+		}
+		if verbose {
+			log.Printf("%s %sGET:%s %s%s\n", _APP_, cerr.Green, cerr.Yellow, plainURL, cerr.Reset)
+		}
+		data, err := aurGet(plainURL)
+		if err != nil {
+			recordFailure(term, err)
+			continue
+		}
+		info, err := srcinfo.ParseBytes(data)
+		if err != nil {
+			recordFailure(term, err)
+			continue
+		}
+		views = append(views, newSrcinfoView(plainURL, info))
+	}
+
+	if len(notFoundNames) > 0 {
+		logError(gettext("Pacote(s) não encontrado(s) no AUR: "), strings.Join(notFoundNames, " "))
+	}
+	if outputFormat == "--ndjson" {
+		for _, view := range views {
+			jsonData, _ := json.Marshal(view)
+			p(string(jsonData))
+		}
+		return
+	}
+	if views == nil {
+		views = []srcinfoView{}
+	}
+	jsonData, err := json.MarshalIndent(views, "", "  ")
+	if err != nil {
+		msgError(gettext("Erro ao formatar saída JSON: ") + err.Error())
+		return
+	}
+	p(string(jsonData))
+}
+
+func newSrcinfoView(file string, info *srcinfo.Srcinfo) srcinfoView {
+	view := srcinfoView{
+		File:         file,
+		Pkgbase:      info.Pkgbase,
+		Version:      info.Version(),
+		Pkgver:       info.Pkgver,
+		Pkgrel:       info.Pkgrel,
+		Epoch:        info.Epoch,
+		MakeDepends:  info.MakeDepends,
+		CheckDepends: info.CheckDepends,
+		ValidPGPKeys: info.ValidPGPKeys,
+		Sources:      info.Sources(),
+	}
+	if view.Sources == nil {
+		view.Sources = []srcinfo.Source{}
+	}
+	for _, source := range view.Sources {
+		view.SkipChecksums = view.SkipChecksums || source.Skip
+	}
+	for _, name := range info.Pkgnames() {
+		pkg, _ := info.Package(name)
+		view.Packages = append(view.Packages, pkg)
+	}
+	return view
+}
+
+// depEdge é uma dependência de um pacote do AUR no --deps-tree
+type depEdge struct {
+	Dependency string `json:"dependency"`     // como está no Depends/MakeDepends
+	Name       string `json:"name,omitempty"` // pacote que satisfaz a dependência
+	Source     string `json:"source"`         // aur, repo ou unresolved
+	Make       bool   `json:"make,omitempty"` // veio do MakeDepends
 }
 
-func searchPackage(term string, searchField string, limit int, wg *sync.WaitGroup, ch chan<- Package) {
-	defer wg.Done()
+// depNode é um pacote do AUR que precisa ser compilado
+type depNode struct {
+	Name         string    `json:"name"`
+	Version      string    `json:"version"`
+	PackageBase  string    `json:"package_base"`
+	Dependencies []depEdge `json:"dependencies"`
+}
 
-	cacheKey := term + "|" + searchField
-	cacheMutex.Lock()
-	if entry, found := cache[cacheKey]; found && time.Since(entry.Timestamp) < cacheTTL {
-		for _, pkg := range entry.Results {
-			// Envia todos os pacotes se limit for <= 0
-			if limit <= 0 || count < limit {
-				ch <- pkg
-				count++
-			} else {
-				break
+// unresolvedDep é uma dependência que nem os repositórios nem o AUR satisfazem
+type unresolvedDep struct {
+	Dependency string `json:"dependency"`
+	RequiredBy string `json:"required_by,omitempty"` // vazio para os pacotes pedidos no -Si
+	Reason     string `json:"reason"`
+}
+
+// depsReport é o resultado do --deps-tree
+type depsReport struct {
+	Roots      []string        `json:"roots"`
+	Packages   []depNode       `json:"packages"` // na ordem de compilação; os presos em ciclos no fim
+	BuildOrder []string        `json:"build_order"`
+	Cycles     [][]string      `json:"cycles"`
+	Unresolved []unresolvedDep `json:"unresolved"`
+	Errors     []termError     `json:"errors"`
+	ElapsedMS  int64           `json:"elapsed_ms"`
+}
+
+// runDepsTree resolve as dependências dos pacotes do -Si e mostra o grafo
+func runDepsTree() {
+	report := resolveDeps(searchTerms, repoProviders())
+	printDepsReport(report)
+	if len(report.Cycles) > 0 || len(report.Unresolved) > 0 {
+		if len(failures) > 0 {
+			os.Exit(exitRequestFailed)
+		}
+		os.Exit(exitDepsProblem)
+	}
+}
+
+// repoProviders indexa os pacotes dos bancos sync pelo nome e pelo que eles
+// proveem (Provides), apontando para o nome do pacote do repositório
+func repoProviders() map[string]string {
+	providers := make(map[string]string)
+	syncPackages, err := alpm.SyncPackages(dbPath)
+	if err != nil {
+		logError(fmt.Sprintf(gettext("Aviso: sem os bancos sync do pacman (%v); todas as dependências serão procuradas no AUR"), err))
+		return providers
+	}
+	for _, pkg := range syncPackages {
+		providers[pkg.Name] = pkg.Name
+	}
+	for _, pkg := range syncPackages {
+		for _, provide := range pkg.Provides {
+			name := dependencyName(provide)
+			if _, ok := providers[name]; !ok {
+				providers[name] = pkg.Name
 			}
 		}
-		cacheMutex.Unlock()
-		return
 	}
-	cacheMutex.Unlock()
+	return providers
+}
 
-	queryParams := url.Values{}
-	queryParams.Add("v", "5")
-	queryParams.Add("type", "search")
-	queryParams.Add("by", searchField)
-	queryParams.Add("arg", term)
+// resolveDeps percorre as dependências em largura: cada nível é consultado
+// com infoDetails (lotes em paralelo) e o que não existe pelo nome é
+// procurado entre os pacotes do AUR que o proveem
+func resolveDeps(roots []string, repo map[string]string) depsReport {
+	report := depsReport{Roots: roots, Unresolved: []unresolvedDep{}}
+	graph := depgraph.New()
+	nodes := make(map[string]*depNode)
+	known := make(map[string]Package) // pacotes do AUR já consultados com info
 
-	// Verificar se algum parâmetro 'by-' foi fornecido
-	if queryParams.Get("by") == "" {
-		fullURL = fmt.Sprintf("%s?v=%s&type=%s&arg=%s", baseURL, queryParams.Get("v"), queryParams.Get("type"), queryParams.Get("arg"))
-	} else {
-		fullURL = fmt.Sprintf("%s?v=%s&type=%s&by=%s&arg=%s", baseURL, queryParams.Get("v"), queryParams.Get("type"), queryParams.Get("by"), queryParams.Get("arg"))
+	lookup := func(pkgNames []string) {
+		var pending []string
+		for _, name := range pkgNames {
+			if _, ok := known[name]; !ok {
+				pending = append(pending, name)
+			}
+		}
+		for name, pkg := range infoDetails(pending) {
+			known[name] = pkg
+		}
+	}
+
+	lookup(roots)
+	var level []string
+	for _, root := range roots {
+		if _, ok := known[root]; !ok {
+			report.Unresolved = append(report.Unresolved, unresolvedDep{Dependency: root, Reason: gettext("não encontrado no AUR")})
+			continue
+		}
+		if _, ok := nodes[root]; !ok {
+			pkg := known[root]
+			nodes[root] = &depNode{Name: pkg.Name, Version: pkg.Version, PackageBase: pkg.PackageBase}
+			graph.AddNode(root)
+			level = append(level, root)
+		}
+	}
+
+	for len(level) > 0 {
+		// Dependências deste nível que não estão nos repositórios
+		var aurNames []string
+		for _, name := range level {
+			for _, dep := range packageDeps(known[name]) {
+				if depName := dependencyName(dep.Dependency); repo[depName] == "" {
+					aurNames = append(aurNames, depName)
+				}
+			}
+		}
+		lookup(aurNames)
+		var missing []string
+		for _, depName := range aurNames {
+			if _, ok := known[depName]; !ok {
+				missing = append(missing, depName)
+			}
+		}
+		providers := aurProviders(missing)
+
+		var next []string
+		for _, name := range level {
+			node := nodes[name]
+			for _, dep := range packageDeps(known[name]) {
+				depName := dependencyName(dep.Dependency)
+				if repoName := repo[depName]; repoName != "" {
+					dep.Name, dep.Source = repoName, "repo"
+				} else if pkg, ok := known[depName]; ok && !satisfiesVersion(dep.Dependency, pkg.Version) {
+					dep.Source = "unresolved"
+					report.Unresolved = append(report.Unresolved, unresolvedDep{
+						Dependency: dep.Dependency,
+						RequiredBy: name,
+						Reason:     fmt.Sprintf(gettext("o AUR tem a versão %s"), pkg.Version),
+					})
+				} else if ok || providers[depName] != "" {
+					dep.Name, dep.Source = depName, "aur"
+					if !ok {
+						dep.Name = providers[depName]
+					}
+					graph.AddEdge(name, dep.Name)
+					if _, queued := nodes[dep.Name]; !queued {
+						nodes[dep.Name] = &depNode{Name: dep.Name}
+						next = append(next, dep.Name)
+					}
+				} else {
+					dep.Source = "unresolved"
+					report.Unresolved = append(report.Unresolved, unresolvedDep{
+						Dependency: dep.Dependency,
+						RequiredBy: name,
+						Reason:     gettext("não encontrado nos repositórios nem no AUR"),
+					})
+				}
+				node.Dependencies = append(node.Dependencies, dep)
+			}
+		}
+
+		// Os provedores vieram de uma busca, que não traz as dependências
+		lookup(next)
+		level = level[:0]
+		for _, name := range next {
+			pkg, ok := known[name]
+			if !ok {
+				continue // falha na consulta, já registrada em failures
+			}
+			nodes[name].Version, nodes[name].PackageBase = pkg.Version, pkg.PackageBase
+			level = append(level, name)
+		}
+	}
+
+	order, cycles := graph.Order()
+	report.BuildOrder = order
+	report.Cycles = cycles
+	if report.BuildOrder == nil {
+		report.BuildOrder = []string{}
+	}
+	if report.Cycles == nil {
+		report.Cycles = [][]string{}
+	}
+	listed := make(map[string]bool)
+	for _, name := range append(order, graph.Nodes()...) {
+		if !listed[name] {
+			listed[name] = true
+			report.Packages = append(report.Packages, *nodes[name])
+		}
+	}
+	return report
+}
+
+// packageDeps retorna as dependências de compilação do pacote: Depends e
+// MakeDepends, sem repetir o mesmo nome
+func packageDeps(pkg Package) []depEdge {
+	var deps []depEdge
+	seen := make(map[string]bool)
+	add := func(list []string, isMake bool) {
+		for _, dep := range list {
+			if name := dependencyName(dep); name != "" && !seen[name] {
+				seen[name] = true
+				deps = append(deps, depEdge{Dependency: dep, Make: isMake})
+			}
+		}
+	}
+	add(pkg.Depends, false)
+	add(pkg.MakeDepends, true)
+	return deps
+}
+
+// aurProviders procura no AUR (by=provides) um pacote para cada nome,
+// escolhendo o mais votado quando houver mais de um
+func aurProviders(depNames []string) map[string]string {
+	providers := make(map[string]string)
+	var mutex sync.Mutex
+	var wg sync.WaitGroup
+	seen := make(map[string]bool)
+	for _, depName := range depNames {
+		if seen[depName] {
+			continue
+		}
+		seen[depName] = true
+		wg.Add(1)
+		go func(depName string) {
+			defer wg.Done()
+			packages, err := searchTerm(depName, "provides")
+			if err != nil {
+				recordFailure(depName, err)
+				return
+			}
+			if len(packages) == 0 {
+				return
+			}
+			best := packages[0]
+			for _, pkg := range packages[1:] {
+				if pkg.NumVotes > best.NumVotes || (pkg.NumVotes == best.NumVotes && pkg.Name < best.Name) {
+					best = pkg
+				}
+			}
+			mutex.Lock()
+			providers[depName] = best.Name
+			mutex.Unlock()
+		}(depName)
+	}
+	wg.Wait()
+	return providers
+}
+
+// satisfiesVersion verifica a restrição de versão da dependência (ex:
+// "libfoo>=2.0") com o vercmp; sem restrição, qualquer versão serve
+func satisfiesVersion(dep, version string) bool {
+	for _, op := range []string{">=", "<=", "=", "<", ">"} {
+		i := strings.Index(dep, op)
+		if i < 0 {
+			continue
+		}
+		cmp := vercmp.Compare(version, strings.TrimSpace(dep[i+len(op):]))
+		switch op {
+		case ">=":
+			return cmp >= 0
+		case "<=":
+			return cmp <= 0
+		case "=":
+			return cmp == 0
+		case "<":
+			return cmp < 0
+		default:
+			return cmp > 0
+		}
+	}
+	return true
+}
+
+// printDepsReport mostra o --deps-tree em JSON (padrão), texto (--text) ou
+// Graphviz DOT (--dot)
+func printDepsReport(report depsReport) {
+	switch outputFormat {
+	case "", "--json":
+		report.Errors = failures
+		if report.Errors == nil {
+			report.Errors = []termError{}
+		}
+		report.ElapsedMS = time.Since(startTime).Milliseconds()
+		jsonData, err := json.MarshalIndent(report, "", "  ")
+		if err != nil {
+			msgError(gettext("Erro ao formatar saída JSON: ") + err.Error())
+			return
+		}
+		p(string(jsonData))
+	case "--dot":
+		printDepsDot(report)
+	default:
+		printDepsText(report)
+	}
+}
+
+// printDepsText desenha a árvore de cada pacote pedido, seguida da ordem de
+// compilação, dos ciclos e do que não foi resolvido
+func printDepsText(report depsReport) {
+	nodes := make(map[string]depNode, len(report.Packages))
+	for _, node := range report.Packages {
+		nodes[node.Name] = node
+	}
+	shown := make(map[string]bool)
+	path := make(map[string]bool)
+
+	var tree func(node depNode, prefix string)
+	tree = func(node depNode, prefix string) {
+		path[node.Name] = true
+		shown[node.Name] = true
+		for i, dep := range node.Dependencies {
+			branch, indent := "├── ", "│   "
+			if i == len(node.Dependencies)-1 {
+				branch, indent = "└── ", "    "
+			}
+			line := dep.Dependency
+			if dep.Name != "" && dep.Name != dependencyName(dep.Dependency) {
+				line += " -> " + dep.Name
+			}
+			child, isNode := nodes[dep.Name]
+			switch {
+			case dep.Source == "repo":
+				line += cout.Cyan + " [repo]" + cout.Reset
+			case dep.Source == "unresolved":
+				line = cout.Red + line + gettext(" [não resolvido]") + cout.Reset
+			case isNode:
+				line += " " + cout.Green + child.Version + cout.Reset
+			}
+			if dep.Make {
+				line += cout.Yellow + " [make]" + cout.Reset
+			}
+			switch {
+			case dep.Source != "aur" || !isNode:
+				p(prefix + branch + line)
+			case path[dep.Name]:
+				p(prefix + branch + line + cout.Red + gettext(" (ciclo)") + cout.Reset)
+			case shown[dep.Name]:
+				p(prefix + branch + line + gettext(" (já listado)"))
+			default:
+				p(prefix + branch + line)
+				tree(child, prefix+indent)
+			}
+		}
+		path[node.Name] = false
+	}
+
+	for _, root := range report.Roots {
+		node, ok := nodes[root]
+		if !ok {
+			continue
+		}
+		p(root + " " + cout.Green + node.Version + cout.Reset)
+		tree(node, "")
+	}
+
+	if len(report.BuildOrder) > 0 {
+		fmt.Printf("%s:: %s%s\n", cout.Yellow, gettext("Ordem de compilação"), cout.Reset)
+		for i, name := range report.BuildOrder {
+			fmt.Printf("   %d. %s\n", i+1, name)
+		}
+	}
+	if len(report.Cycles) > 0 {
+		fmt.Printf("%s:: %s%s\n", cout.Yellow, gettext("Ciclos de dependências"), cout.Reset)
+		for _, cycle := range report.Cycles {
+			p("   " + strings.Join(cycle, " -> "))
+		}
+	}
+	if len(report.Unresolved) > 0 {
+		fmt.Printf("%s:: %s%s\n", cout.Yellow, gettext("Dependências não resolvidas"), cout.Reset)
+		for _, dep := range report.Unresolved {
+			line := "   " + dep.Dependency
+			if dep.RequiredBy != "" {
+				line += fmt.Sprintf(gettext(" (requerido por %s)"), dep.RequiredBy)
+			}
+			p(line + ": " + dep.Reason)
+		}
+	}
+}
+
+// printDepsDot gera o grafo no formato DOT do Graphviz. Pacotes do
+// repositório ficam tracejados, os não resolvidos em vermelho e as
+// dependências de compilação (MakeDepends) com aresta tracejada.
+func printDepsDot(report depsReport) {
+	var sb strings.Builder
+	sb.WriteString("digraph " + dotQuote(_APP_) + " {\n")
+	sb.WriteString("\trankdir=LR;\n")
+	sb.WriteString("\tnode [shape=box];\n")
+	declared := make(map[string]bool)
+	for _, node := range report.Packages {
+		declared[node.Name] = true
+		fmt.Fprintf(&sb, "\t%s [label=%s];\n", dotQuote(node.Name), dotQuote(node.Name+"\n"+node.Version))
+	}
+	for _, node := range report.Packages {
+		for _, dep := range node.Dependencies {
+			target := dep.Name
+			if target == "" {
+				target = dep.Dependency
+			}
+			if !declared[target] {
+				declared[target] = true
+				if dep.Source == "repo" {
+					fmt.Fprintf(&sb, "\t%s [style=dashed, color=gray];\n", dotQuote(target))
+				} else {
+					fmt.Fprintf(&sb, "\t%s [color=red, fontcolor=red];\n", dotQuote(target))
+				}
+			}
+			var attrs []string
+			if dep.Make {
+				attrs = append(attrs, "style=dashed")
+			}
+			if dep.Name != "" && dep.Name != dependencyName(dep.Dependency) {
+				attrs = append(attrs, "label="+dotQuote(dep.Dependency))
+			}
+			edge := "\t" + dotQuote(node.Name) + " -> " + dotQuote(target)
+			if len(attrs) > 0 {
+				edge += " [" + strings.Join(attrs, ", ") + "]"
+			}
+			sb.WriteString(edge + ";\n")
+		}
+	}
+	sb.WriteString("}")
+	p(sb.String())
+}
+
+// dotQuote coloca o identificador entre aspas duplas, como exige o DOT
+func dotQuote(value string) string {
+	value = strings.ReplaceAll(value, `\`, `\\`)
+	value = strings.ReplaceAll(value, `"`, `\"`)
+	value = strings.ReplaceAll(value, "\n", `\n`)
+	return `"` + value + `"`
+}
+
+// httpStatusError é a resposta do AUR com código HTTP diferente de 200
+type httpStatusError struct {
+	StatusCode int
+	Status     string
+	RetryAfter time.Duration
+}
+
+func (e *httpStatusError) Error() string {
+	return gettext("resposta ") + e.Status
+}
+
+// termError registra a falha de um termo (ou lote de pacotes do -Si); vai
+// para o campo errors da saída --json
+type termError struct {
+	Term    string `json:"term"`
+	Status  int    `json:"status,omitempty"` // código HTTP, se houve resposta
+	Message string `json:"message"`
+}
+
+var (
+	failures      []termError
+	failuresMutex sync.Mutex
+)
+
+// recordFailure guarda a falha para o código de saída e mostra a mensagem
+func recordFailure(term string, err error) {
+	failure := termError{Term: term, Message: err.Error()}
+	var statusErr *httpStatusError
+	if errors.As(err, &statusErr) {
+		failure.Status = statusErr.StatusCode
+	}
+	failuresMutex.Lock()
+	failures = append(failures, failure)
+	failuresMutex.Unlock()
+	fmt.Fprintf(os.Stderr, gettext("%sErro na busca pelo termo '%s': %s%s\n"), cerr.Red, term, err, cerr.Reset)
+}
+
+// Cliente HTTP compartilhado por todas as requisições ao AUR
+var (
+	connectTimeout = 10 * time.Second
+	requestTimeout = 30 * time.Second
+	maxRetries     = 3
+	maxRequests    = 4 // requisições simultâneas ao AUR
+	retryBaseDelay = 500 * time.Millisecond
+	retryMaxDelay  = 30 * time.Second
+
+	httpClient     *http.Client
+	requestSlots   chan struct{}
+	httpClientOnce sync.Once
+)
+
+func initHTTPClient() {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.DialContext = (&net.Dialer{Timeout: connectTimeout, KeepAlive: 30 * time.Second}).DialContext
+	transport.TLSHandshakeTimeout = connectTimeout
+	transport.MaxIdleConnsPerHost = maxRequests
+	httpClient = &http.Client{Transport: transport, Timeout: requestTimeout}
+	requestSlots = make(chan struct{}, maxRequests)
+}
+
+// getOptions ajusta uma chamada ao aurGetWith sem mexer nos valores globais
+// das opções --timeout e --retries.
+type getOptions struct {
+	timeout time.Duration // tempo limite de cada tentativa, conexão incluída (0 = requestTimeout)
+	retries int           // tentativas extras após uma falha temporária
+}
+
+// aurGet faz o GET limitando o número de requisições simultâneas e repetindo,
+// com backoff exponencial e jitter, as falhas de rede, 429 e 5xx. Quando o
+// servidor envia Retry-After, esse tempo é respeitado.
+func aurGet(fullURL string) ([]byte, error) {
+	return aurGetWith(fullURL, getOptions{retries: maxRetries})
+}
+
+// aurGetWith é o aurGet com tempo limite e tentativas próprios da chamada.
+func aurGetWith(fullURL string, opts getOptions) ([]byte, error) {
+	httpClientOnce.Do(initHTTPClient)
+	requestSlots <- struct{}{}
+	defer func() { <-requestSlots }()
+
+	for attempt := 0; ; attempt++ {
+		data, err := aurGetOnce(fullURL, opts.timeout)
+		if err == nil || attempt >= opts.retries || !retryable(err) {
+			return data, err
+		}
+		delay := retryDelay(attempt, err)
+		if verbose {
+			log.Printf(gettext("%s %sretry:%s %s em %s (%s)%s\n"), _APP_, cerr.Yellow, cerr.Reset, fullURL, delay.Round(time.Millisecond), err, cerr.Reset)
+		}
+		time.Sleep(delay)
 	}
+}
 
-	resp, err := http.Get(fullURL)
+func aurGetOnce(fullURL string, timeout time.Duration) ([]byte, error) {
+	ctx := context.Background()
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, fullURL, nil)
 	if err != nil {
-		fmt.Printf("Erro ao fazer a requisição para o termo '%s': %s\n", term, err)
-		return
+		return nil, err
+	}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, err
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		fmt.Printf("Erro na resposta para o termo '%s': %s\n", term, resp.Status)
-		return
+		io.Copy(io.Discard, resp.Body)
+		return nil, &httpStatusError{
+			StatusCode: resp.StatusCode,
+			Status:     resp.Status,
+			RetryAfter: parseRetryAfter(resp.Header.Get("Retry-After")),
+		}
 	}
+	return io.ReadAll(resp.Body)
+}
+
+func retryable(err error) bool {
+	var statusErr *httpStatusError
+	if errors.As(err, &statusErr) {
+		return statusErr.StatusCode == http.StatusTooManyRequests || statusErr.StatusCode >= 500
+	}
+	return true // erro de rede ou timeout
+}
+
+// retryDelay calcula a espera antes da próxima tentativa: o Retry-After do
+// servidor ou um valor aleatório entre 0 e retryBaseDelay*2^attempt
+func retryDelay(attempt int, err error) time.Duration {
+	var statusErr *httpStatusError
+	if errors.As(err, &statusErr) && statusErr.RetryAfter > 0 {
+		return min(statusErr.RetryAfter, retryMaxDelay)
+	}
+	backoff := min(retryBaseDelay<<attempt, retryMaxDelay)
+	return time.Duration(rand.Int64N(int64(backoff) + 1))
+}
+
+// parseRetryAfter aceita os dois formatos do cabeçalho: segundos ou data HTTP
+func parseRetryAfter(value string) time.Duration {
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds > 0 {
+		return time.Duration(seconds) * time.Second
+	}
+	if date, err := http.ParseTime(value); err == nil {
+		return max(time.Until(date), 0)
+	}
+	return 0
+}
 
-	data, err := ioutil.ReadAll(resp.Body)
+// rpcResults consulta o AUR RPC e decodifica o campo results
+func rpcResults(fullURL string) ([]Package, error) {
+	data, err := aurGet(fullURL)
 	if err != nil {
-		fmt.Printf("Erro ao ler a resposta para o termo '%s': %s\n", term, err)
-		return
+		return nil, err
 	}
 
-	var response map[string]interface{}
+	var response struct {
+		Results []Package `json:"results"`
+		Error   string    `json:"error"`
+	}
 	if err := json.Unmarshal(data, &response); err != nil {
-		fmt.Printf("Erro ao decodificar o JSON para o termo '%s': %s\n", term, err)
+		return nil, fmt.Errorf(gettext("erro ao decodificar o JSON: %w"), err)
+	}
+	if response.Error != "" {
+		return nil, errors.New(response.Error)
+	}
+	return response.Results, nil
+}
+
+func getStringField(data map[string]interface{}, key string) string {
+	if value, ok := data[key]; ok {
+		switch v := value.(type) {
+		case string:
+			return v
+		case float64:
+			return fmt.Sprintf("%f", v)
+		case bool:
+			return fmt.Sprintf("%t", v)
+		default:
+			return fmt.Sprintf("%v", v)
+		}
+	}
+	return ""
+}
+
+func searchPackage(term string, searchField string, wg *sync.WaitGroup, ch chan<- Package) {
+	defer wg.Done()
+
+	packages, err := searchTerm(term, searchField)
+	if err != nil {
+		recordFailure(term, err)
 		return
 	}
+	sendPackages(packages, searchField, ch)
+}
 
-	results, ok := response["results"].([]interface{})
-	if !ok {
-		fmt.Printf("Campo 'results' não encontrado na resposta para o termo '%s'\n", term)
+// sendPackages envia os pacotes ao canal; o --limit é aplicado depois da
+// ordenação, em runSearchPackages
+func sendPackages(packages []Package, searchField string, ch chan<- Package) {
+	for _, pkg := range packages {
+		if verbose {
+			pkg.fullURL = searchURL(pkg.Name, searchField)
+		}
+		ch <- pkg
+	}
+}
+
+// searchURL monta a URL do tipo search, com o parâmetro by apenas se algum
+// --by-* foi fornecido
+func searchURL(term string, searchField string) string {
+	if searchField == "" {
+		return fmt.Sprintf("%s?v=5&type=search&arg=%s", baseURL, url.QueryEscape(term))
+	}
+	return fmt.Sprintf("%s?v=5&type=search&by=%s&arg=%s", baseURL, searchField, url.QueryEscape(term))
+}
+
+// searchTerm retorna a resposta completa do AUR para o termo, usando o cache
+// em disco quando possível
+func searchTerm(term string, searchField string) ([]Package, error) {
+	if packages, found := cacheGet("search", term, searchField); found {
+		return packages, nil
+	}
+
+	packages, err := rpcResults(searchURL(term, searchField))
+	if err != nil {
+		return nil, err
+	}
+
+	cachePut("search", term, searchField, packages)
+	return packages, nil
+}
+
+// searchAllTerms implementa o --all-terms: consulta o AUR apenas com o termo
+// mais seletivo e filtra localmente para que todos os termos coincidam
+func searchAllTerms(terms []string, searchField string, wg *sync.WaitGroup, ch chan<- Package) {
+	defer wg.Done()
+
+	primary := mostSelectiveTerm(terms)
+	packages, err := searchTerm(primary, searchField)
+	if err != nil {
+		recordFailure(primary, err)
 		return
 	}
 
-	for _, result := range results {
-		// Verifica se o limite é maior que zero e se o contador é menor que o limite
-		if limit > 0 && count >= limit {
-			break
+	// A resposta do tipo search não traz as listas de dependências; para
+	// filtrar por elas é preciso buscar os detalhes de cada candidato
+	if strings.HasSuffix(searchField, "depends") {
+		names := make([]string, 0, len(packages))
+		for _, pkg := range packages {
+			names = append(names, pkg.Name)
+		}
+		details := infoDetails(names)
+		for i, pkg := range packages {
+			if detail, ok := details[pkg.Name]; ok {
+				packages[i] = detail
+			}
 		}
+	}
 
-		pkgData, err := json.Marshal(result)
-		if err != nil {
-			fmt.Printf("Erro ao converter o resultado para o termo '%s': %s\n", term, err)
-			return
+	var matched []Package
+	for _, pkg := range packages {
+		if matchesAllTerms(pkg, terms, searchField) {
+			matched = append(matched, pkg)
 		}
+	}
+	sendPackages(matched, searchField, ch)
+}
 
-		var pkg Package
-		if err := json.Unmarshal(pkgData, &pkg); err != nil {
-			fmt.Printf("Erro ao decodificar o pacote para o termo '%s': %s\n", term, err)
-			return
+// mostSelectiveTerm escolhe o termo mais longo, que no AUR costuma ser o que
+// retorna menos resultados
+func mostSelectiveTerm(terms []string) string {
+	best := terms[0]
+	for _, term := range terms[1:] {
+		if len(term) > len(best) {
+			best = term
 		}
+	}
+	return best
+}
 
-		count++
-		if verbose {
-			// Verificar se algum parâmetro 'by-' foi fornecido
-			if queryParams.Get("by") == "" {
-				fullURL = fmt.Sprintf("%s?v=%s&type=%s&arg=%s", baseURL, queryParams.Get("v"), queryParams.Get("type"), pkg.Name)
-			} else {
-				fullURL = fmt.Sprintf("%s?v=%s&type=%s&by=%s&arg=%s", baseURL, queryParams.Get("v"), queryParams.Get("type"), queryParams.Get("by"), pkg.Name)
-			}
-			pkg.fullURL = fullURL
-			pkg.count = count
+// matchesAllTerms verifica se todos os termos coincidem com o campo do --by-*
+func matchesAllTerms(pkg Package, terms []string, searchField string) bool {
+	for _, term := range terms {
+		if !matchesTerm(pkg, term, searchField) {
+			return false
 		}
+	}
+	return true
+}
 
-		ch <- pkg
+// matchesTerm reproduz localmente o critério que o AUR usa para cada campo by
+func matchesTerm(pkg Package, term string, searchField string) bool {
+	lowerTerm := strings.ToLower(term)
+	switch searchField {
+	case "name":
+		return strings.Contains(strings.ToLower(pkg.Name), lowerTerm)
+	case "maintainer":
+		return strings.EqualFold(pkg.Maintainer, term)
+	case "depends":
+		return hasDependency(pkg.Depends, term)
+	case "makedepends":
+		return hasDependency(pkg.MakeDepends, term)
+	case "optdepends":
+		return hasDependency(pkg.OptDepends, term)
+	case "checkdepends":
+		return hasDependency(pkg.CheckDepends, term)
+	default: // "name-desc", padrão do AUR
+		return strings.Contains(strings.ToLower(pkg.Name), lowerTerm) ||
+			strings.Contains(strings.ToLower(pkg.Description), lowerTerm)
+	}
+}
+
+// hasDependency compara apenas o nome da dependência, sem a restrição de
+// versão ("foo>=1.0") nem a descrição das opcionais ("foo: para ...")
+func hasDependency(deps []string, name string) bool {
+	for _, dep := range deps {
+		if strings.EqualFold(dependencyName(dep), name) {
+			return true
+		}
+	}
+	return false
+}
+
+func dependencyName(dep string) string {
+	if i := strings.IndexAny(dep, "<>=:"); i >= 0 {
+		dep = dep[:i]
+	}
+	return strings.TrimSpace(dep)
+}
+
+// cacheGet busca no cache em disco a resposta de baseURL para (modo, termo,
+// campo by)
+func cacheGet(mode, term, by string) ([]Package, bool) {
+	if noCache || refreshCache {
+		return nil, false
+	}
+	entry, found := diskCache.Get(cache.Key(baseURL, mode, term, by))
+	if !found {
+		return nil, false
+	}
+	var packages []Package
+	if err := json.Unmarshal(entry.Data, &packages); err != nil {
+		return nil, false
+	}
+	if verbose {
+		log.Printf("%s %scache:%s '%s' (%s)%s\n", _APP_, cerr.Green, cerr.Yellow, term, mode, cerr.Reset)
+	}
+	return packages, true
+}
+
+// cachePut grava no cache em disco a resposta de baseURL para (modo, termo,
+// campo by)
+func cachePut(mode, term, by string, packages []Package) {
+	if noCache {
+		return
 	}
+	data, err := json.Marshal(packages)
+	if err != nil {
+		return
+	}
+	entry := cache.Entry{Endpoint: baseURL, Mode: mode, Term: term, By: by, Data: data}
+	if err := diskCache.Put(cache.Key(baseURL, mode, term, by), entry); err != nil && verbose {
+		logError(gettext("Erro ao gravar o cache: "), err)
+	}
+}
 
-	cacheMutex.Lock()
-	cache[cacheKey] = CacheEntry{Results: nil, Timestamp: time.Now()} // Cache vazio pois já foi processado
-	cacheMutex.Unlock()
+func printCacheStats() {
+	st, err := diskCache.Stats()
+	if err != nil {
+		logError(gettext("Erro ao ler o cache: "), err)
+		return
+	}
+	if outputFormat == "--json" {
+		jsonData, err := json.MarshalIndent(st, "", "  ")
+		if err != nil {
+			msgError(gettext("Erro ao formatar saída JSON: ") + err.Error())
+			return
+		}
+		p(string(jsonData))
+		return
+	}
+	fmt.Printf("%s%-12s %s%s%s\n", cout.Blue, gettext("Diretório"), cout.Reset, st.Dir, cout.Reset)
+	fmt.Printf("%s%-12s %s%s%s\n", cout.Blue, "TTL", cout.Reset, st.TTL, cout.Reset)
+	fmt.Printf("%s%-12s %s%d/%d%s\n", cout.Blue, gettext("Entradas"), cout.Reset, st.Entries, st.MaxEntries, cout.Reset)
+	fmt.Printf("%s%-12s %s%d%s\n", cout.Blue, gettext("Expiradas"), cout.Reset, st.Expired, cout.Reset)
+	fmt.Printf("%s%-12s %s%d/%d bytes%s\n", cout.Blue, gettext("Tamanho"), cout.Reset, st.Bytes, st.MaxBytes, cout.Reset)
+	if st.Oldest != nil && st.Newest != nil {
+		fmt.Printf("%s%-12s %s%s%s\n", cout.Blue, gettext("Mais antiga"), cout.Reset, st.Oldest.Format(time.DateTime), cout.Reset)
+		fmt.Printf("%s%-12s %s%s%s\n", cout.Blue, gettext("Mais nova"), cout.Reset, st.Newest.Format(time.DateTime), cout.Reset)
+	}
+	fmt.Printf("%s%-12s %s%d%s\n", cout.Blue, gettext("Acertos"), cout.Reset, st.Hits, cout.Reset)
+	fmt.Printf("%s%-12s %s%d%s\n", cout.Blue, gettext("Falhas"), cout.Reset, st.Misses, cout.Reset)
 }
@@ -0,0 +1,938 @@
+package main
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"io/fs"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/vcatafesta/chili-big-go/big-search-aur/internal/alpm"
+	"github.com/vcatafesta/chili-big-go/big-search-aur/internal/aurtest"
+	"github.com/vcatafesta/chili-big-go/big-search-aur/internal/cache"
+	"github.com/vcatafesta/chili-big-go/big-search-aur/internal/i18n"
+	"srcinfo"
+)
+
+// fakeAUR aponta o big-search-aur para o servidor falso, sem cache em disco
+func fakeAUR(t *testing.T) *aurtest.Server {
+	t.Helper()
+	srv := aurtest.NewServer()
+	oldURL, oldNoCache, oldDelay := baseURL, noCache, retryBaseDelay
+	baseURL, noCache, retryBaseDelay = srv.RPCURL, true, time.Millisecond
+	t.Cleanup(func() {
+		baseURL, noCache, retryBaseDelay = oldURL, oldNoCache, oldDelay
+		failures = nil
+		srv.Close()
+	})
+	return srv
+}
+
+// collect executa um produtor e devolve os pacotes enviados ao canal
+func collect(run func(wg *sync.WaitGroup, ch chan<- Package)) []Package {
+	ch := make(chan Package)
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go run(&wg, ch)
+	go func() {
+		wg.Wait()
+		close(ch)
+	}()
+	var packages []Package
+	for pkg := range ch {
+		packages = append(packages, pkg)
+	}
+	return packages
+}
+
+func names(packages []Package) string {
+	var list []string
+	for _, pkg := range packages {
+		list = append(list, pkg.Name)
+	}
+	return strings.Join(list, " ")
+}
+
+func TestSearchTerm(t *testing.T) {
+	fakeAUR(t)
+
+	packages, err := searchTerm("brave", "name")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := names(packages); got != "brave-bin brave-nightly-bin" {
+		t.Errorf("searchTerm(brave) = %q", got)
+	}
+	if packages[1].OutOfDate == nil || *packages[1].OutOfDate != 1725200000 {
+		t.Errorf("OutOfDate não decodificado: %v", packages[1].OutOfDate)
+	}
+
+	packages, err = searchTerm("nada-encontrado", "")
+	if err != nil || len(packages) != 0 {
+		t.Errorf("searchTerm(nada-encontrado) = %v, %v", packages, err)
+	}
+}
+
+func TestSearchTermErrors(t *testing.T) {
+	var tt = []struct {
+		term  string
+		fault *aurtest.Fault
+	}{
+		{term: "limitado", fault: &aurtest.Fault{Status: 429}},
+		{term: "quebrado", fault: &aurtest.Fault{Status: 503}},
+		{term: "interno", fault: &aurtest.Fault{Status: 500, Body: "<html>erro</html>"}},
+		{term: "malformed"},
+		{term: "x"}, // "Query arg too small."
+	}
+
+	srv := fakeAUR(t)
+	for _, tc := range tt {
+		if tc.fault != nil {
+			srv.Fail(tc.term, *tc.fault)
+		}
+		if packages, err := searchTerm(tc.term, ""); err == nil {
+			t.Errorf("searchTerm(%q) = %v, esperava erro", tc.term, packages)
+		}
+	}
+}
+
+// TestCacheEndpoint confere que o cache em disco separa as respostas por
+// servidor: trocar o --aur-url não pode devolver o que veio de outro AUR
+func TestCacheEndpoint(t *testing.T) {
+	first := fakeAUR(t)
+	second := aurtest.NewServerFS(fstest.MapFS{})
+	t.Cleanup(second.Close)
+
+	oldCache := diskCache
+	diskCache = &cache.Cache{Dir: t.TempDir(), TTL: time.Hour}
+	noCache = false
+	t.Cleanup(func() { diskCache = oldCache })
+
+	if packages, err := searchTerm("brave", "name"); err != nil || len(packages) != 2 {
+		t.Fatalf("primeiro servidor: %v, %v", names(packages), err)
+	}
+	if got := strings.Join(suggestPackages("chili-"), " "); got != "chili-app chili-build" {
+		t.Fatalf("suggest no primeiro servidor: %q", got)
+	}
+
+	baseURL = second.RPCURL
+	if packages, err := searchTerm("brave", "name"); err != nil || len(packages) != 0 {
+		t.Errorf("segundo servidor recebeu a resposta do primeiro: %v, %v", names(packages), err)
+	}
+	if got := suggestPackages("chili-"); len(got) != 0 {
+		t.Errorf("suggest no segundo servidor recebeu a resposta do primeiro: %q", got)
+	}
+	if len(second.Requests()) != 2 {
+		t.Errorf("segundo servidor recebeu %d requisições, esperado 2", len(second.Requests()))
+	}
+
+	// De volta ao primeiro, as respostas continuam no cache
+	requests := len(first.Requests())
+	baseURL = first.RPCURL
+	if packages, _ := searchTerm("brave", "name"); len(packages) != 2 {
+		t.Errorf("cache do primeiro servidor perdido: %v", names(packages))
+	}
+	if len(first.Requests()) != requests {
+		t.Error("o primeiro servidor foi consultado apesar do cache")
+	}
+}
+
+func TestInfoPackage(t *testing.T) {
+	srv := fakeAUR(t)
+
+	packages := collect(func(wg *sync.WaitGroup, ch chan<- Package) {
+		infoPackage([]string{"yay", "nao-existe", "elisa-git", "brave-bin", "yay"}, wg, ch)
+	})
+	if got := names(packages); got != "yay elisa-git brave-bin" {
+		t.Errorf("infoPackage = %q, esperava a ordem da linha de comando", got)
+	}
+	if got := strings.Join(packages[1].MakeDepends, " "); got != "git extra-cmake-modules kdoctools" {
+		t.Errorf("MakeDepends de elisa-git = %q", got)
+	}
+	if n := len(srv.Requests()); n != 1 {
+		t.Errorf("infoPackage fez %d requisições, esperava 1 lote", n)
+	}
+}
+
+func TestInfoPackageFailure(t *testing.T) {
+	srv := fakeAUR(t)
+	srv.Fail("yay", aurtest.Fault{Status: 502})
+
+	packages := collect(func(wg *sync.WaitGroup, ch chan<- Package) {
+		infoPackage([]string{"yay", "brave-bin"}, wg, ch)
+	})
+	if len(packages) != 0 {
+		t.Errorf("infoPackage com 502 = %q", names(packages))
+	}
+}
+
+func TestRetry(t *testing.T) {
+	srv := fakeAUR(t)
+	srv.Fail("brave", aurtest.Fault{Status: 429, RetryAfter: "0", Times: 2})
+	srv.Fail("yay", aurtest.Fault{Status: 503, Times: 1})
+
+	for _, term := range []string{"brave", "yay"} {
+		packages, err := searchTerm(term, "")
+		if err != nil || len(packages) != 2 {
+			t.Errorf("searchTerm(%q) após falhas temporárias = %q, %v", term, names(packages), err)
+		}
+	}
+	if n := len(srv.Requests()); n != 5 {
+		t.Errorf("%d requisições, esperava 5 (3 para brave e 2 para yay)", n)
+	}
+}
+
+func TestRetryGivesUp(t *testing.T) {
+	srv := fakeAUR(t)
+	srv.Fail("brave", aurtest.Fault{Status: 500})
+	srv.Fail("yay", aurtest.Fault{Status: 404})
+
+	packages := collect(func(wg *sync.WaitGroup, ch chan<- Package) {
+		searchPackage("brave", "", wg, ch)
+	})
+	if len(packages) != 0 || len(failures) != 1 || failures[0].Status != 500 {
+		t.Errorf("searchPackage(brave) = %q, falhas %+v", names(packages), failures)
+	}
+	if n := len(srv.Requests()); n != maxRetries+1 {
+		t.Errorf("%d requisições, esperava %d", n, maxRetries+1)
+	}
+
+	// 404 não é repetido
+	if _, err := searchTerm("yay", ""); err == nil {
+		t.Error("searchTerm(yay) com 404 não retornou erro")
+	}
+	if n := len(srv.Requests()); n != maxRetries+2 {
+		t.Errorf("%d requisições após o 404, esperava %d", n, maxRetries+2)
+	}
+
+	// O suggest não repete a falha nem altera as opções das outras buscas
+	srv.Fail("chili-", aurtest.Fault{Status: 500})
+	retries, timeout := maxRetries, requestTimeout
+	if got := suggestPackages("chili-"); len(got) != 0 {
+		t.Errorf("suggestPackages com 500 = %q", got)
+	}
+	if n := len(srv.Requests()); n != maxRetries+3 {
+		t.Errorf("%d requisições após o suggest, esperava %d", n, maxRetries+3)
+	}
+	if maxRetries != retries || requestTimeout != timeout {
+		t.Errorf("suggestPackages alterou maxRetries/requestTimeout: %d, %s", maxRetries, requestTimeout)
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	var tt = []struct {
+		input    string
+		expected time.Duration
+	}{
+		{"", 0},
+		{"7", 7 * time.Second},
+		{"-1", 0},
+		{"abc", 0},
+		{time.Now().Add(-time.Hour).UTC().Format(http.TimeFormat), 0},
+	}
+	for _, tc := range tt {
+		if got := parseRetryAfter(tc.input); got != tc.expected {
+			t.Errorf("parseRetryAfter(%q) = %s, esperava %s", tc.input, got, tc.expected)
+		}
+	}
+	future := time.Now().Add(time.Minute).UTC().Format(http.TimeFormat)
+	if got := parseRetryAfter(future); got < 55*time.Second || got > time.Minute {
+		t.Errorf("parseRetryAfter(%q) = %s", future, got)
+	}
+}
+
+func TestInfoBatches(t *testing.T) {
+	var pkgNames []string
+	for i := 0; i < 500; i++ {
+		pkgNames = append(pkgNames, strings.Repeat("p", 20)+"-"+strings.Repeat("x", i%7))
+	}
+	batches := infoBatches(pkgNames)
+	if len(batches) < 2 {
+		t.Fatalf("infoBatches gerou %d lote(s) para 500 nomes", len(batches))
+	}
+	total := 0
+	for _, batch := range batches {
+		if n := len(infoURL(batch)); n > maxURLLength {
+			t.Errorf("URL do lote com %d bytes, limite é %d", n, maxURLLength)
+		}
+		total += len(batch)
+	}
+	if total != len(pkgNames) {
+		t.Errorf("lotes com %d nomes, esperava %d", total, len(pkgNames))
+	}
+}
+
+// TestFetchInfoErrors confere que o onError recebe cada lote que falhou, uma
+// chamada por vez (rode com -race)
+func TestFetchInfoErrors(t *testing.T) {
+	srv := fakeAUR(t)
+	var pkgNames []string
+	for i := 0; i < 500; i++ {
+		pkgNames = append(pkgNames, fmt.Sprintf("%s-%03d", strings.Repeat("p", 20), i))
+	}
+	batches := infoBatches(pkgNames)
+	for _, batch := range batches {
+		srv.Fail(batch[0], aurtest.Fault{Status: 400})
+	}
+
+	var failed [][]string
+	found := fetchInfo(pkgNames, func(batch []string, err error) {
+		failed = append(failed, batch)
+	})
+	if len(found) != 0 {
+		t.Errorf("fetchInfo encontrou %d pacotes, esperado 0", len(found))
+	}
+	if len(failed) != len(batches) {
+		t.Errorf("onError chamado %d vezes, esperado %d", len(failed), len(batches))
+	}
+}
+
+func TestSearchAllTerms(t *testing.T) {
+	fakeAUR(t)
+
+	packages := collect(func(wg *sync.WaitGroup, ch chan<- Package) {
+		searchAllTerms([]string{"brave", "nig"}, "", wg, ch)
+	})
+	if got := names(packages); got != "brave-nightly-bin" {
+		t.Errorf("searchAllTerms(brave nig) = %q", got)
+	}
+}
+
+func TestSortPackages(t *testing.T) {
+	packages := []Package{
+		{Name: "yay-bin", NumVotes: 501, Description: "AUR helper"},
+		{Name: "paru", NumVotes: 900, Description: "yay alternative"},
+		{Name: "yay", NumVotes: 2411},
+		{Name: "yaycache", NumVotes: 3},
+	}
+
+	sortPackages(packages, "relevance", []string{"yay"}, false)
+	if got := names(packages); got != "yay yay-bin yaycache paru" {
+		t.Errorf("relevance = %q", got)
+	}
+	sortPackages(packages, "votes", nil, false)
+	if got := names(packages); got != "yay paru yay-bin yaycache" {
+		t.Errorf("votes = %q", got)
+	}
+	sortPackages(packages, "name", nil, true)
+	if got := names(packages); got != "yaycache yay-bin yay paru" {
+		t.Errorf("name --reverse = %q", got)
+	}
+}
+
+func TestShellQuote(t *testing.T) {
+	bash, err := exec.LookPath("bash")
+	if err != nil {
+		t.Skip("bash não encontrado")
+	}
+	values := []string{
+		"",
+		"simples",
+		"It's a 'quoted' word",
+		"$(touch /tmp/big-search-aur-injected) `id` ${HOME}",
+		"linha 1\nlinha 2\ttab \\ barra",
+		"controle \x01 e aspas ' juntos",
+	}
+	for _, value := range values {
+		script := "declare -A pkg=([Description]=" + shellQuote(value) + "); printf '%s' \"${pkg[Description]}\""
+		if strings.Contains(shellQuote(value), "\n") && !strings.HasPrefix(shellQuote(value), "$'") {
+			t.Errorf("shellQuote(%q) gerou mais de uma linha", value)
+		}
+		out, err := exec.Command(bash, "-c", script).Output()
+		if err != nil {
+			t.Fatalf("bash -c %q: %v", script, err)
+		}
+		if string(out) != value {
+			t.Errorf("shellQuote(%q) avaliado pelo bash = %q", value, out)
+		}
+	}
+}
+
+func TestEscapeRaw(t *testing.T) {
+	bash, err := exec.LookPath("bash")
+	if err != nil {
+		t.Skip("bash não encontrado")
+	}
+	fields := []string{"a|b", `c\d`, "e", "f|"}
+	var escaped []string
+	for _, field := range fields {
+		escaped = append(escaped, escapeRaw(field, "|"))
+	}
+	line := strings.Join(escaped, "|")
+	script := "IFS='|' read one two three four <<<" + singleQuote(line) + "; printf '%s\\n' \"$one\" \"$two\" \"$three\" \"$four\""
+	out, err := exec.Command(bash, "-c", script).Output()
+	if err != nil {
+		t.Fatalf("bash -c %q: %v", script, err)
+	}
+	if got := strings.Split(strings.TrimSuffix(string(out), "\n"), "\n"); strings.Join(got, ",") != strings.Join(fields, ",") {
+		t.Errorf("read de %q = %q, esperava %q", line, got, fields)
+	}
+}
+
+// pacmanDB cria um dbpath com os pacotes instalados (nome -> versão) e um
+// banco sync core.db contendo os nomes de repo
+func pacmanDB(t *testing.T, installed map[string]string, repo []string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for name, version := range installed {
+		pkgDir := filepath.Join(dir, "local", name+"-"+version)
+		if err := os.MkdirAll(pkgDir, 0o755); err != nil {
+			t.Fatal(err)
+		}
+		desc := "%NAME%\n" + name + "\n\n%VERSION%\n" + version + "\n\n"
+		if err := os.WriteFile(filepath.Join(pkgDir, "desc"), []byte(desc), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := os.MkdirAll(filepath.Join(dir, "sync"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	f, err := os.Create(filepath.Join(dir, "sync", "core.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	gz := gzip.NewWriter(f)
+	tw := tar.NewWriter(gz)
+	for _, name := range repo {
+		desc := []byte("%FILENAME%\n" + name + "-1-1-x86_64.pkg.tar.zst\n\n%NAME%\n" + name + "\n\n%VERSION%\n1-1\n\n")
+		tw.WriteHeader(&tar.Header{Name: name + "-1-1/", Typeflag: tar.TypeDir, Mode: 0o755})
+		tw.WriteHeader(&tar.Header{Name: name + "-1-1/desc", Typeflag: tar.TypeReg, Mode: 0o644, Size: int64(len(desc))})
+		tw.Write(desc)
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := gz.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return dir
+}
+
+func TestCheckUpgrades(t *testing.T) {
+	fakeAUR(t)
+	dir := pacmanDB(t, map[string]string{
+		"glibc":             "2.40-1",
+		"yay":               "12.3.1-1",
+		"brave-bin":         "1:1.70.0-1", // versão local mais nova que a do AUR
+		"brave-nightly-bin": "1.71.54-1",
+		"meu-pacote":        "0.1-1",
+	}, []string{"glibc"})
+	// Um banco zstd é pulado sem impedir a leitura do core.db
+	zstd := []byte{0x28, 0xb5, 0x2f, 0xfd, 0, 0, 0, 0}
+	if err := os.WriteFile(filepath.Join(dir, "sync", "extra.db"), zstd, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	foreign, err := alpm.ForeignPackages(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var pkgNames []string
+	for _, pkg := range foreign {
+		pkgNames = append(pkgNames, pkg.Name)
+	}
+	if got := strings.Join(pkgNames, " "); got != "brave-bin brave-nightly-bin meu-pacote yay" {
+		t.Fatalf("ForeignPackages = %q", got)
+	}
+
+	report := checkUpgrades(foreign, infoDetails(pkgNames))
+	if len(report.Upgradable) != 1 || report.Upgradable[0].Name != "yay" || report.Upgradable[0].AURVersion != "12.3.5-1" {
+		t.Errorf("Upgradable = %+v", report.Upgradable)
+	}
+	if len(report.Missing) != 1 || report.Missing[0].Name != "meu-pacote" {
+		t.Errorf("Missing = %+v", report.Missing)
+	}
+	if len(report.OutOfDate) != 1 || report.OutOfDate[0].Name != "brave-nightly-bin" {
+		t.Errorf("OutOfDate = %+v", report.OutOfDate)
+	}
+
+	if _, err := alpm.ForeignPackages(t.TempDir()); err == nil {
+		t.Error("ForeignPackages sem banco local não retornou erro")
+	}
+}
+
+func TestResolveDeps(t *testing.T) {
+	fakeAUR(t)
+	repo := map[string]string{"glibc": "glibc", "bash": "bash", "sh": "bash"}
+
+	report := resolveDeps([]string{"chili-app", "ciclo-a", "nada"}, repo)
+	if got := strings.Join(report.BuildOrder, " "); got != "chili-build libchili ttf-chili chili-app" {
+		t.Errorf("BuildOrder = %q", got)
+	}
+	if len(report.Cycles) != 1 || strings.Join(report.Cycles[0], " ") != "ciclo-a ciclo-b ciclo-a" {
+		t.Errorf("Cycles = %v", report.Cycles)
+	}
+
+	var unresolved []string
+	for _, dep := range report.Unresolved {
+		unresolved = append(unresolved, dep.Dependency+"@"+dep.RequiredBy)
+	}
+	if got := strings.Join(unresolved, " "); got != "nada@ nao-existe@chili-app libchili>=3@ciclo-a" {
+		t.Errorf("Unresolved = %q", got)
+	}
+
+	for _, node := range report.Packages {
+		if node.Name != "chili-app" {
+			continue
+		}
+		var deps []string
+		for _, dep := range node.Dependencies {
+			deps = append(deps, dep.Dependency+"="+dep.Name+":"+dep.Source)
+		}
+		expected := "libchili>=2=libchili:aur glibc=glibc:repo fonte-virtual=ttf-chili:aur nao-existe=:unresolved chili-build=chili-build:aur"
+		if got := strings.Join(deps, " "); got != expected {
+			t.Errorf("dependências de chili-app = %q", got)
+		}
+	}
+}
+
+func TestRdepsPackage(t *testing.T) {
+	srv := fakeAUR(t)
+
+	packages := collect(func(wg *sync.WaitGroup, ch chan<- Package) {
+		rdepsPackage([]string{"chili-build"}, wg, ch)
+	})
+	if got := names(packages); got != "chili-app libchili" {
+		t.Fatalf("rdepsPackage(chili-build) = %q", got)
+	}
+	var relations []string
+	for _, relation := range packages[0].Relations {
+		relations = append(relations, relation.Kind+"="+relation.Dependency)
+	}
+	if got := strings.Join(relations, ","); got != "makedepends=chili-build,optdepends=chili-build: para recompilar os plugins" {
+		t.Errorf("Relations de chili-app = %q", got)
+	}
+	if got := fieldString(packages[1], "Relations"); got != "makedepends:chili-build" {
+		t.Errorf("Relations de libchili = %q", got)
+	}
+	// quatro buscas e um lote info
+	if n := len(srv.Requests()); n != len(relationKinds)+1 {
+		t.Errorf("%d requisições, esperava %d", n, len(relationKinds)+1)
+	}
+}
+
+// tarball monta um tar.gz com as entradas dadas (nome -> conteúdo); conteúdo
+// iniciado por "->" vira um link simbólico
+func tarball(t *testing.T, entries ...string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	for i := 0; i+1 < len(entries); i += 2 {
+		name, content := entries[i], entries[i+1]
+		header := &tar.Header{Name: name, Typeflag: tar.TypeReg, Mode: 0o644, Size: int64(len(content))}
+		if link, ok := strings.CutPrefix(content, "->"); ok {
+			header = &tar.Header{Name: name, Typeflag: tar.TypeSymlink, Linkname: link, Mode: 0o777}
+		}
+		if err := tw.WriteHeader(header); err != nil {
+			t.Fatal(err)
+		}
+		if header.Typeflag == tar.TypeReg {
+			tw.Write([]byte(content))
+		}
+	}
+	tw.Close()
+	gz.Close()
+	return buf.Bytes()
+}
+
+// chdirTemp muda para um diretório temporário dentro de outro, para que os
+// testes de path traversal tenham onde verificar o que escapou
+func chdirTemp(t *testing.T) string {
+	t.Helper()
+	outer := t.TempDir()
+	dir := filepath.Join(outer, "work")
+	if err := os.Mkdir(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	old, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.Chdir(old) })
+	return outer
+}
+
+func TestGetSnapshot(t *testing.T) {
+	srv := fakeAUR(t)
+	chdirTemp(t)
+	pkg := infoDetails([]string{"yay"})["yay"]
+
+	if status, err := getSnapshot(pkg, "yay"); status != "downloaded" || err != nil {
+		t.Fatalf("getSnapshot(yay) = %s, %v", status, err)
+	}
+	for _, name := range []string{"yay/PKGBUILD", "yay/.SRCINFO"} {
+		if _, err := os.Stat(name); err != nil {
+			t.Errorf("%s não foi extraído: %v", name, err)
+		}
+	}
+
+	// Mesma versão: não baixa de novo
+	requests := len(srv.Requests())
+	if status, err := getSnapshot(pkg, "yay"); status != "up-to-date" || err != nil {
+		t.Errorf("getSnapshot(yay) atualizado = %s, %v", status, err)
+	}
+	if n := len(srv.Requests()); n != requests {
+		t.Errorf("snapshot atualizado fez %d requisição(ões)", n-requests)
+	}
+
+	// Versão antiga no .SRCINFO: baixa e mantém o que o makepkg criou
+	os.WriteFile("yay/.SRCINFO", []byte("pkgbase = yay\n\tpkgver = 12.3.1\n\tpkgrel = 1\n\npkgname = yay\n"), 0o644)
+	os.WriteFile("yay/yay-12.3.1.tar.gz", []byte("fonte"), 0o644)
+	if status, err := getSnapshot(pkg, "yay"); status != "downloaded" || err != nil {
+		t.Errorf("getSnapshot(yay) desatualizado = %s, %v", status, err)
+	}
+	if info, err := srcinfo.ParseFile("yay/.SRCINFO"); err != nil || info.Version() != "12.3.5-1" {
+		t.Errorf(".SRCINFO após atualizar = %+v, %v", info, err)
+	}
+	if _, err := os.Stat("yay/yay-12.3.1.tar.gz"); err != nil {
+		t.Errorf("arquivo fora do snapshot foi apagado: %v", err)
+	}
+
+	// Diretório que não veio do AUR só é sobrescrito com --force
+	os.Mkdir("brave-bin", 0o755)
+	brave := infoDetails([]string{"brave-bin"})["brave-bin"]
+	srv.SetSnapshot("brave-bin", tarball(t, "brave-bin/PKGBUILD", "pkgname=brave-bin\n"))
+	if status, err := getSnapshot(brave, "brave-bin"); status != "failed" || err == nil {
+		t.Errorf("getSnapshot em diretório existente = %s, %v", status, err)
+	}
+	force = true
+	defer func() { force = false }()
+	if status, err := getSnapshot(brave, "brave-bin"); status != "downloaded" || err != nil {
+		t.Errorf("getSnapshot --force = %s, %v", status, err)
+	}
+}
+
+// TestGetSnapshotPrefix usa um mirror do AUR servido abaixo de /aur: o
+// snapshot tem que vir de /aur/cgit/..., não de /cgit/... na raiz
+func TestGetSnapshotPrefix(t *testing.T) {
+	srv := fakeAUR(t)
+	mirror := httptest.NewServer(http.StripPrefix("/aur", srv.Config.Handler))
+	t.Cleanup(mirror.Close)
+	baseURL = mirror.URL + "/aur/rpc"
+	chdirTemp(t)
+
+	pkg := infoDetails([]string{"yay"})["yay"]
+	if status, err := getSnapshot(pkg, "yay"); status != "downloaded" || err != nil {
+		t.Fatalf("getSnapshot(yay) no mirror com prefixo = %s, %v", status, err)
+	}
+	if _, err := os.Stat("yay/PKGBUILD"); err != nil {
+		t.Errorf("yay/PKGBUILD não foi extraído: %v", err)
+	}
+
+	for urlPath, want := range map[string]string{
+		"/cgit/aur.git/snapshot/yay.tar.gz":  mirror.URL + "/aur/cgit/aur.git/snapshot/yay.tar.gz",
+		"/cgit/aur.git/plain/.SRCINFO?h=yay": mirror.URL + "/aur/cgit/aur.git/plain/.SRCINFO?h=yay",
+		"https://example.org/yay.tar.gz":     "https://example.org/yay.tar.gz",
+	} {
+		if got, err := aurWebURL(urlPath); got != want || err != nil {
+			t.Errorf("aurWebURL(%q) = %q, %v, esperado %q", urlPath, got, err, want)
+		}
+	}
+}
+
+func TestGetSnapshotUnsafe(t *testing.T) {
+	var tt = []struct {
+		name string
+		data func(t *testing.T) []byte
+	}{
+		{"html", func(t *testing.T) []byte { return []byte("<html>502 Bad Gateway</html>") }},
+		{"traversal", func(t *testing.T) []byte { return tarball(t, "yay/PKGBUILD", "ok", "yay/../../escapou", "x") }},
+		{"absoluto", func(t *testing.T) []byte { return tarball(t, "/tmp/escapou", "x") }},
+		{"outra base", func(t *testing.T) []byte { return tarball(t, "yay/PKGBUILD", "ok", "outro/PKGBUILD", "x") }},
+		{"link para fora", func(t *testing.T) []byte { return tarball(t, "yay/fora", "->../..", "yay/PKGBUILD", "ok") }},
+		{"link absoluto", func(t *testing.T) []byte { return tarball(t, "yay/etc", "->/etc") }},
+		{"escrita por link", func(t *testing.T) []byte { return tarball(t, "yay/sub", "->.", "yay/sub/PKGBUILD", "x") }},
+		{"link por link", func(t *testing.T) []byte { return tarball(t, "yay/d", "->.", "yay/x", "->d/../escapou") }},
+	}
+
+	srv := fakeAUR(t)
+	outer := chdirTemp(t)
+	pkg := infoDetails([]string{"yay"})["yay"]
+	for _, tc := range tt {
+		srv.SetSnapshot("yay", tc.data(t))
+		status, err := getSnapshot(pkg, "yay")
+		if status != "failed" || err == nil {
+			t.Errorf("%s: getSnapshot = %s, %v", tc.name, status, err)
+		}
+		if _, err := os.Stat("yay"); err == nil {
+			t.Errorf("%s: ./yay criado a partir de um snapshot inválido", tc.name)
+			os.RemoveAll("yay")
+		}
+		if _, err := os.Lstat(filepath.Join(outer, "escapou")); err == nil {
+			t.Errorf("%s: arquivo escrito fora do destino", tc.name)
+		}
+	}
+}
+
+func TestRunSrcinfo(t *testing.T) {
+	fakeAUR(t)
+	chdirTemp(t)
+	os.Mkdir("local", 0o755)
+	os.WriteFile("local/.SRCINFO", []byte("pkgbase = local\n\tpkgver = 1\n\tpkgrel = 1\n\tsource = git+https://example.org/local.git\n\tsha256sums = SKIP\n\npkgname = local\n"), 0o644)
+
+	old := searchTerms
+	defer func() { searchTerms = old }()
+	searchTerms = []string{"yay", "local"}
+	views := captureJSON[[]srcinfoView](t, runSrcinfo)
+	if len(views) != 2 || views[0].Pkgbase != "yay" || views[1].Pkgbase != "local" {
+		t.Fatalf("runSrcinfo = %+v", views)
+	}
+	if views[0].Version != "12.3.5-1" || views[0].SkipChecksums || len(views[0].Sources) != 1 || views[0].Sources[0].Filename != "yay-12.3.5.tar.gz" {
+		t.Errorf("yay = %+v", views[0])
+	}
+	if !views[1].SkipChecksums || views[1].Packages[0].Pkgname != "local" {
+		t.Errorf("local = %+v", views[1])
+	}
+}
+
+// captureJSON executa run com a saída padrão redirecionada e decodifica o JSON
+func captureJSON[T any](t *testing.T, run func()) T {
+	t.Helper()
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	stdout := os.Stdout
+	os.Stdout = w
+	run()
+	os.Stdout = stdout
+	w.Close()
+	var value T
+	if err := json.NewDecoder(r).Decode(&value); err != nil {
+		t.Fatalf("saída não é JSON: %v", err)
+	}
+	return value
+}
+
+func TestLoadConfig(t *testing.T) {
+	configDir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", configDir)
+	config := `format = raw
+sep = ;
+limit = 50
+verbose = true
+
+[bigstore]
+format = json
+by = name-desc
+fields = name,Version,NumVotes
+limit = 10
+`
+	if err := os.WriteFile(filepath.Join(configDir, _APP_+".conf"), []byte(config), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	oldFormat, oldSep, oldLimit, oldVerbose, oldField, oldFields := outputFormat, separator, limit, verbose, searchField, selectedFields
+	t.Cleanup(func() {
+		outputFormat, separator, limit, verbose, searchField, selectedFields = oldFormat, oldSep, oldLimit, oldVerbose, oldField, oldFields
+	})
+
+	if !loadConfig("") {
+		t.Fatal("loadConfig sem perfil falhou")
+	}
+	if outputFormat != "--raw" || separator != ";" || limit != 50 || !verbose || searchField != "" {
+		t.Errorf("sem perfil: format=%q sep=%q limit=%d verbose=%v by=%q", outputFormat, separator, limit, verbose, searchField)
+	}
+
+	if !loadConfig("bigstore") {
+		t.Fatal("loadConfig(bigstore) falhou")
+	}
+	if outputFormat != "--json" || separator != ";" || limit != 10 || searchField != "name-desc" || strings.Join(selectedFields, ",") != "Name,Version,NumVotes" {
+		t.Errorf("perfil bigstore: format=%q sep=%q limit=%d by=%q fields=%v", outputFormat, separator, limit, searchField, selectedFields)
+	}
+
+	// As opções da linha de comando vêm depois e prevalecem
+	args, nlenArgs = []string{"--profile", "bigstore", "-Ss", "yay", "--limit", "3", "--raw"}, 7
+	if !parseArgs() {
+		t.Fatal("parseArgs falhou")
+	}
+	if limit != 3 || outputFormat != "--raw" || strings.Join(searchTerms, " ") != "yay" {
+		t.Errorf("linha de comando: limit=%d format=%q termos=%q", limit, outputFormat, searchTerms)
+	}
+	searchTerms, searchMode = nil, ""
+
+	// --profile sem valor no fim da linha de comando
+	if profile, ok := profileArg([]string{"-Ss", "yay", "--profile"}); ok {
+		t.Errorf("profileArg com --profile no fim = %q, sem erro", profile)
+	}
+	if profile, ok := profileArg([]string{"--profile", "bigstore", "-Ss"}); !ok || profile != "bigstore" {
+		t.Errorf("profileArg = %q, %v", profile, ok)
+	}
+	args, nlenArgs = []string{"-Ss", "yay", "--profile"}, 3
+	if parseArgs() {
+		t.Error("parseArgs com --profile sem valor não falhou")
+	}
+	searchTerms, searchMode = nil, ""
+
+	if loadConfig("nao-existe") {
+		t.Error("loadConfig com perfil inexistente não falhou")
+	}
+}
+
+func TestCompletionScript(t *testing.T) {
+	for _, shell := range completionShells {
+		script := completionScript(shell)
+		for _, flag := range flagDefs {
+			for _, name := range flag.Names {
+				if shell == "fish" {
+					name = fishOption(name)
+				}
+				if !flag.Hidden && !strings.Contains(script, name) {
+					t.Errorf("%s: falta a opção %q no script", shell, name)
+				}
+			}
+		}
+	}
+
+	bash, err := exec.LookPath("bash")
+	if err != nil {
+		t.Skip("bash não encontrado")
+	}
+	cases := []struct{ line, want string }{
+		{"big-search-aur --so", "--sort"},
+		{"big-search-aur --sort p", "popularity"},
+		{"big-search-aur --fields Name,Ver", "Name,Version"},
+		{"big-search-aur --completion f", "fish"},
+	}
+	for _, c := range cases {
+		script := completionScript("bash") + `
+COMP_WORDS=(` + c.line + `)
+COMP_CWORD=$((${#COMP_WORDS[@]} - 1))
+_big_search_aur big-search-aur 2>/dev/null
+printf '%s\n' "${COMPREPLY[@]}"`
+		out, err := exec.Command(bash, "-c", script).Output()
+		if err != nil {
+			t.Fatalf("%q: %v", c.line, err)
+		}
+		if got := strings.TrimSpace(string(out)); got != c.want {
+			t.Errorf("%q: completou %q, esperado %q", c.line, got, c.want)
+		}
+	}
+}
+
+func TestCompletePackages(t *testing.T) {
+	srv := fakeAUR(t)
+	oldFiles := packagesMetaFiles
+	t.Cleanup(func() { packagesMetaFiles = oldFiles })
+
+	// Sem lista packages-meta, usa o suggest do AUR
+	packagesMetaFiles = []string{filepath.Join(t.TempDir(), "nao-existe.json.gz")}
+	if got := strings.Join(completePackages("chili-"), " "); got != "chili-app chili-build" {
+		t.Errorf("suggest: %q", got)
+	}
+	if completePackages("") != nil {
+		t.Error("prefixo vazio consultou o suggest")
+	}
+
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	gz.Write([]byte(`[{"ID":1,"Name":"yay-bin","Depends":["git"]},{"ID":2,"Name":"brave-bin"},{"ID":3,"Name":"yay"}]`))
+	gz.Close()
+	packagesMetaFiles[0] = filepath.Join(t.TempDir(), "packages-meta-v1.json.gz")
+	if err := os.WriteFile(packagesMetaFiles[0], buf.Bytes(), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	requests := len(srv.Requests())
+	if got := strings.Join(completePackages("yay"), " "); got != "yay yay-bin" {
+		t.Errorf("packages-meta: %q", got)
+	}
+	if len(srv.Requests()) != requests {
+		t.Error("com a lista packages-meta ainda consultou o AUR")
+	}
+}
+
+// TestMessageCatalogs confere se cada gettext("...") do código, inclusive dos
+// pacotes em internal/, tem tradução em todos os catálogos, com os mesmos
+// verbos de formatação
+func TestMessageCatalogs(t *testing.T) {
+	fset := token.NewFileSet()
+	var files []*ast.File
+	err := filepath.WalkDir(".", func(name string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() || !strings.HasSuffix(name, ".go") || strings.HasSuffix(name, "_test.go") {
+			return err
+		}
+		file, err := parser.ParseFile(fset, name, nil, parser.ParseComments)
+		if err != nil {
+			return err
+		}
+		// As versões antigas (big-search-aur-vN.go) não entram no binário
+		for _, group := range file.Comments {
+			if group.Pos() < file.Package && strings.Contains(group.Text(), "go:build ignore") {
+				return nil
+			}
+		}
+		files = append(files, file)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	msgids := make(map[string]token.Position)
+	for _, file := range files {
+		ast.Inspect(file, func(n ast.Node) bool {
+			call, ok := n.(*ast.CallExpr)
+			if !ok {
+				return true
+			}
+			if ident, ok := call.Fun.(*ast.Ident); !ok || ident.Name != "gettext" {
+				return true
+			}
+			lit, ok := call.Args[0].(*ast.BasicLit)
+			if !ok || lit.Kind != token.STRING {
+				t.Errorf("%s: gettext sem literal, o xgettext não extrai", fset.Position(call.Pos()))
+				return true
+			}
+			msgid, _ := strconv.Unquote(lit.Value)
+			msgids[msgid] = fset.Position(lit.Pos())
+			return true
+		})
+	}
+
+	verbs := regexp.MustCompile(`%[-+# 0-9.]*[a-zA-Z%]`)
+	for _, lang := range i18n.Languages() {
+		data, err := fs.ReadFile(os.DirFS("internal/i18n/po"), lang+".po")
+		if err != nil {
+			t.Fatal(err)
+		}
+		catalog, err := i18n.Parse(bytes.NewReader(data))
+		if err != nil {
+			t.Fatalf("%s.po: %v", lang, err)
+		}
+		for msgid, pos := range msgids {
+			msgstr, ok := catalog[msgid]
+			if !ok {
+				t.Errorf("%s.po: falta %q (%s)", lang, msgid, pos)
+				continue
+			}
+			if got, want := verbs.FindAllString(msgstr, -1), verbs.FindAllString(msgid, -1); strings.Join(got, " ") != strings.Join(want, " ") {
+				t.Errorf("%s.po: %q tem os verbos %v, esperado %v", lang, msgid, got, want)
+			}
+		}
+		for msgid := range catalog {
+			if _, ok := msgids[msgid]; !ok {
+				t.Errorf("%s.po: %q não é mais usado", lang, msgid)
+			}
+		}
+	}
+}
@@ -0,0 +1,18 @@
+module github.com/vcatafesta/chili-big-go/big-search-aur
+
+go 1.23.0
+
+require (
+	colors v0.0.0
+	github.com/go-ini/ini v1.67.0
+	srcinfo v0.0.0
+	vercmp v0.0.0
+)
+
+require github.com/stretchr/testify v1.9.0 // indirect
+
+replace colors => ../colors
+
+replace srcinfo => ../srcinfo
+
+replace vercmp => ../vercmp
@@ -0,0 +1,220 @@
+/*
+  alpm.go - leitura dos bancos de dados local e sync do pacman
+    Chili GNU/Linux - https://github.com/vcatafesta/chili/go
+    Chili GNU/Linux - https://chililinux.com
+    Chili GNU/Linux - https://chilios.com.br
+
+  Created: 2026/10/17
+  Altered: 2026/10/17
+
+  Copyright (c) 2024-2026, Vilmar Catafesta <vcatafesta@gmail.com>
+  All rights reserved.
+
+  Redistribution and use in source and binary forms, with or without
+  modification, are permitted provided that the following conditions
+  are met:
+  1. Redistributions of source code must retain the above copyright
+    notice, this list of conditions and the following disclaimer.
+  2. Redistributions in binary form must reproduce the above copyright
+    notice, this list of conditions and the following disclaimer in the
+    documentation and/or other materials provided with the distribution.
+
+  THIS SOFTWARE IS PROVIDED BY THE AUTHOR ``AS IS'' AND ANY EXPRESS OR
+  IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED WARRANTIES
+  OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE DISCLAIMED.
+  IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR ANY DIRECT, INDIRECT,
+  INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING, BUT
+  NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE,
+  DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY
+  THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT
+  (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF
+  THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+*/
+
+// Package alpm lê os bancos de dados do pacman sem depender da libalpm:
+// o banco local (<dbpath>/local/<nome>-<versão>/desc) e os bancos sync
+// (<dbpath>/sync/*.db, arquivos tar compactados com gzip).
+package alpm
+
+import (
+	"archive/tar"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/vcatafesta/chili-big-go/big-search-aur/internal/i18n"
+)
+
+// DefaultDBPath é o diretório padrão dos bancos de dados do pacman
+const DefaultDBPath = "/var/lib/pacman"
+
+// Package é o subconjunto do desc usado pelo big-search-aur
+type Package struct {
+	Name     string
+	Version  string
+	Provides []string
+}
+
+// As mensagens passam pelo catálogo do big-search-aur (internal/i18n)
+var gettext = i18n.Gettext
+
+// ErrNoSyncDB indica que não há nenhum banco sync para separar os pacotes
+// estrangeiros (pacman -Sy nunca foi executado ou o dbpath está errado)
+var ErrNoSyncDB = errors.New(gettext("nenhum banco de dados sync encontrado"))
+
+// errZstd indica um banco sync comprimido com zstd, que o SyncPackages pula
+var errZstd = errors.New("zstd")
+
+// LocalPackages lê todos os pacotes instalados, ordenados pelo nome
+func LocalPackages(dbPath string) ([]Package, error) {
+	dirs, err := filepath.Glob(filepath.Join(dbPath, "local", "*", "desc"))
+	if err != nil {
+		return nil, err
+	}
+	if len(dirs) == 0 {
+		if _, err := os.Stat(filepath.Join(dbPath, "local")); err != nil {
+			return nil, err
+		}
+	}
+	var packages []Package
+	for _, descPath := range dirs {
+		data, err := os.ReadFile(descPath)
+		if err != nil {
+			return nil, err
+		}
+		pkg := parseDesc(data)
+		if pkg.Name == "" {
+			return nil, fmt.Errorf(gettext("%s: sem %%NAME%%"), descPath)
+		}
+		packages = append(packages, pkg)
+	}
+	sort.Slice(packages, func(i, j int) bool { return packages[i].Name < packages[j].Name })
+	return packages, nil
+}
+
+// SyncPackages lê os pacotes de todos os bancos sync, indexados pelo nome.
+// Um banco comprimido com zstd é ignorado com um aviso no stderr; se nenhum
+// puder ser lido, retorna ErrNoSyncDB.
+func SyncPackages(dbPath string) (map[string]Package, error) {
+	files, err := filepath.Glob(filepath.Join(dbPath, "sync", "*.db"))
+	if err != nil {
+		return nil, err
+	}
+	packages := make(map[string]Package)
+	var read int
+	for _, file := range files {
+		err := readSyncDB(file, packages)
+		if errors.Is(err, errZstd) {
+			fmt.Fprintf(os.Stderr, gettext("Aviso: %s: compressão zstd não suportada, banco ignorado\n"), file)
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", file, err)
+		}
+		read++
+	}
+	if read == 0 {
+		return nil, ErrNoSyncDB
+	}
+	return packages, nil
+}
+
+// ForeignPackages retorna os pacotes instalados que não existem em nenhum
+// banco sync, o equivalente ao pacman -Qm
+func ForeignPackages(dbPath string) ([]Package, error) {
+	local, err := LocalPackages(dbPath)
+	if err != nil {
+		return nil, err
+	}
+	sync, err := SyncPackages(dbPath)
+	if err != nil {
+		return nil, err
+	}
+	var foreign []Package
+	for _, pkg := range local {
+		if _, ok := sync[pkg.Name]; !ok {
+			foreign = append(foreign, pkg)
+		}
+	}
+	return foreign, nil
+}
+
+// readSyncDB lê os arquivos desc de um banco sync (tar com ou sem gzip)
+func readSyncDB(file string, packages map[string]Package) error {
+	f, err := os.Open(file)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	reader := bufio.NewReader(f)
+	var stream io.Reader = reader
+	magic, _ := reader.Peek(4)
+	switch {
+	case bytes.HasPrefix(magic, []byte{0x1f, 0x8b}):
+		gz, err := gzip.NewReader(reader)
+		if err != nil {
+			return err
+		}
+		defer gz.Close()
+		stream = gz
+	case bytes.Equal(magic, []byte{0x28, 0xb5, 0x2f, 0xfd}):
+		return errZstd
+	}
+
+	tr := tar.NewReader(stream)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if header.Typeflag != tar.TypeReg || path.Base(header.Name) != "desc" {
+			continue
+		}
+		data, err := io.ReadAll(tr)
+		if err != nil {
+			return err
+		}
+		if pkg := parseDesc(data); pkg.Name != "" {
+			packages[pkg.Name] = pkg
+		}
+	}
+}
+
+// parseDesc interpreta o formato desc: seções %CHAVE% seguidas de um valor
+// por linha e terminadas por uma linha em branco
+func parseDesc(data []byte) Package {
+	var pkg Package
+	var section string
+	for _, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimRight(line, "\r")
+		if line == "" {
+			section = ""
+			continue
+		}
+		if section == "" && strings.HasPrefix(line, "%") && strings.HasSuffix(line, "%") {
+			section = line
+			continue
+		}
+		switch section {
+		case "%NAME%":
+			pkg.Name = line
+		case "%VERSION%":
+			pkg.Version = line
+		case "%PROVIDES%":
+			pkg.Provides = append(pkg.Provides, line)
+		}
+	}
+	return pkg
+}
@@ -0,0 +1,337 @@
+/*
+  aurtest.go - servidor AUR RPC falso para testes offline do big-search-aur
+    Chili GNU/Linux - https://github.com/vcatafesta/chili/go
+    Chili GNU/Linux - https://chililinux.com
+    Chili GNU/Linux - https://chilios.com.br
+
+  Created: 2026/10/17
+  Altered: 2026/10/17
+
+  Copyright (c) 2024-2026, Vilmar Catafesta <vcatafesta@gmail.com>
+  All rights reserved.
+
+  Redistribution and use in source and binary forms, with or without
+  modification, are permitted provided that the following conditions
+  are met:
+  1. Redistributions of source code must retain the above copyright
+    notice, this list of conditions and the following disclaimer.
+  2. Redistributions in binary form must reproduce the above copyright
+    notice, this list of conditions and the following disclaimer in the
+    documentation and/or other materials provided with the distribution.
+
+  THIS SOFTWARE IS PROVIDED BY THE AUTHOR ``AS IS'' AND ANY EXPRESS OR
+  IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED WARRANTIES
+  OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE DISCLAIMED.
+  IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR ANY DIRECT, INDIRECT,
+  INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING, BUT
+  NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE,
+  DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY
+  THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT
+  (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF
+  THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+*/
+
+// Package aurtest fornece um servidor httptest que imita o AUR RPC v5
+// (/rpc?v=5&type=search|info|suggest) a partir de respostas JSON gravadas.
+//
+// Layout das fixtures:
+//
+//	search/<by>/<arg>.json  resposta completa de uma busca (by padrão: name-desc)
+//	info/<nome>.json        objeto de um pacote, usado para montar respostas info
+//	snapshot/<base>/        arquivos servidos em /cgit/aur.git/snapshot/<base>.tar.gz
+//	                        e em /cgit/aur.git/plain/<arquivo>?h=<base>
+//
+// Falhas (429, 5xx, corpo inválido) são injetadas com Server.Fail; snapshots
+// arbitrários (por exemplo, com path traversal) com Server.SetSnapshot.
+package aurtest
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"embed"
+	"encoding/json"
+	"io/fs"
+	"net/http"
+	"net/http/httptest"
+	"path"
+	"strings"
+	"sync"
+)
+
+// all: para incluir os arquivos ocultos dos snapshots (.SRCINFO)
+//
+//go:embed all:testdata
+var fixtures embed.FS
+
+// Fixtures retorna as respostas gravadas que acompanham o pacote.
+func Fixtures() fs.FS {
+	sub, err := fs.Sub(fixtures, "testdata")
+	if err != nil {
+		panic(err)
+	}
+	return sub
+}
+
+// Fault descreve uma resposta de erro para um argumento (termo ou pacote).
+type Fault struct {
+	Status     int    // código HTTP devolvido
+	Body       string // corpo da resposta (vazio = texto do status)
+	RetryAfter string // valor do cabeçalho Retry-After, se houver
+	Times      int    // quantas vezes falhar antes de responder normalmente (0 = sempre)
+}
+
+// Server é o AUR falso. RPCURL é o endereço a usar no lugar de
+// https://aur.archlinux.org/rpc.
+type Server struct {
+	*httptest.Server
+	RPCURL string
+
+	fixtures  fs.FS
+	mutex     sync.Mutex
+	faults    map[string]*Fault
+	snapshots map[string][]byte
+	requests  []string
+}
+
+// NewServer inicia um servidor com as fixtures embutidas.
+func NewServer() *Server {
+	return NewServerFS(Fixtures())
+}
+
+// NewServerFS inicia um servidor usando as fixtures de fsys.
+func NewServerFS(fsys fs.FS) *Server {
+	s := &Server{fixtures: fsys, faults: make(map[string]*Fault), snapshots: make(map[string][]byte)}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/rpc", s.handleRPC)
+	mux.HandleFunc("/rpc/", s.handleRPC)
+	mux.HandleFunc("/cgit/aur.git/snapshot/", s.handleSnapshot)
+	mux.HandleFunc("/cgit/aur.git/plain/", s.handlePlain)
+	s.Server = httptest.NewServer(mux)
+	s.RPCURL = s.Server.URL + "/rpc"
+	return s
+}
+
+// Fail faz com que as requisições com o argumento arg recebam a falha f.
+func (s *Server) Fail(arg string, f Fault) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.faults[arg] = &f
+}
+
+// SetSnapshot faz com que o snapshot de base responda com data, no lugar do
+// tar.gz gerado a partir de snapshot/<base>/.
+func (s *Server) SetSnapshot(base string, data []byte) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.snapshots[base] = data
+}
+
+// Requests retorna as URLs (caminho + query) recebidas até agora.
+func (s *Server) Requests() []string {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return append([]string(nil), s.requests...)
+}
+
+func (s *Server) handleRPC(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	if s.fail(w, r, append(query["arg[]"], query["arg"]...)) {
+		return
+	}
+
+	switch query.Get("type") {
+	case "search":
+		s.search(w, query.Get("by"), query.Get("arg"))
+	case "info", "multiinfo":
+		s.info(w, query["arg[]"])
+	case "suggest":
+		s.suggest(w, query.Get("arg"))
+	default:
+		writeError(w, "Incorrect request type specified.")
+	}
+}
+
+// handleSnapshot serve /cgit/aur.git/snapshot/<base>.tar.gz
+func (s *Server) handleSnapshot(w http.ResponseWriter, r *http.Request) {
+	base, ok := strings.CutSuffix(path.Base(r.URL.Path), ".tar.gz")
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+	if s.fail(w, r, []string{base}) {
+		return
+	}
+
+	s.mutex.Lock()
+	data, ok := s.snapshots[base]
+	s.mutex.Unlock()
+	if !ok {
+		var err error
+		if data, err = s.snapshotTarball(base); err != nil {
+			http.NotFound(w, r)
+			return
+		}
+	}
+	w.Header().Set("Content-Type", "application/x-gzip")
+	w.Write(data)
+}
+
+// handlePlain serve um arquivo do repositório git do pacote, como
+// /cgit/aur.git/plain/.SRCINFO?h=<base>
+func (s *Server) handlePlain(w http.ResponseWriter, r *http.Request) {
+	base := r.URL.Query().Get("h")
+	if s.fail(w, r, []string{base}) {
+		return
+	}
+	name := strings.TrimPrefix(r.URL.Path, "/cgit/aur.git/plain/")
+	data, err := fs.ReadFile(s.fixtures, path.Join("snapshot", base, name))
+	if base == "" || err != nil {
+		http.NotFound(w, r)
+		return
+	}
+	w.Header().Set("Content-Type", "text/plain; charset=UTF-8")
+	w.Write(data)
+}
+
+// snapshotTarball gera o tar.gz de snapshot/<base>/ como o cgit: todas as
+// entradas dentro de <base>/
+func (s *Server) snapshotTarball(base string) ([]byte, error) {
+	root := path.Join("snapshot", base)
+	if _, err := fs.Stat(s.fixtures, root); err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	err := fs.WalkDir(s.fixtures, root, func(name string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		entryName := path.Join(base, strings.TrimPrefix(name, root))
+		if d.IsDir() {
+			return tw.WriteHeader(&tar.Header{Name: entryName + "/", Typeflag: tar.TypeDir, Mode: 0o755})
+		}
+		data, err := fs.ReadFile(s.fixtures, name)
+		if err != nil {
+			return err
+		}
+		if err := tw.WriteHeader(&tar.Header{Name: entryName, Typeflag: tar.TypeReg, Mode: 0o644, Size: int64(len(data))}); err != nil {
+			return err
+		}
+		_, err = tw.Write(data)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := tw.Close(); err != nil {
+		return nil, err
+	}
+	if err := gz.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// fail registra a requisição e, se algum dos argumentos tiver uma falha
+// programada, responde com ela e retorna true
+func (s *Server) fail(w http.ResponseWriter, r *http.Request, args []string) bool {
+	s.mutex.Lock()
+	s.requests = append(s.requests, r.URL.RequestURI())
+	var fault *Fault
+	for _, arg := range args {
+		if f, ok := s.faults[arg]; ok {
+			fault = f
+			if f.Times > 0 {
+				f.Times--
+				if f.Times == 0 {
+					delete(s.faults, arg)
+				}
+			}
+			break
+		}
+	}
+	s.mutex.Unlock()
+
+	if fault == nil {
+		return false
+	}
+	if fault.RetryAfter != "" {
+		w.Header().Set("Retry-After", fault.RetryAfter)
+	}
+	body := fault.Body
+	if body == "" {
+		body = http.StatusText(fault.Status)
+	}
+	w.WriteHeader(fault.Status)
+	w.Write([]byte(body))
+	return true
+}
+
+func (s *Server) search(w http.ResponseWriter, by, arg string) {
+	if len(arg) < 2 {
+		writeError(w, "Query arg too small.")
+		return
+	}
+	if by == "" {
+		by = "name-desc"
+	}
+	data, err := fs.ReadFile(s.fixtures, path.Join("search", by, arg+".json"))
+	if err != nil {
+		writeJSON(w, response{Type: "search", Version: 5, Results: []json.RawMessage{}})
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(data)
+}
+
+func (s *Server) info(w http.ResponseWriter, names []string) {
+	results := []json.RawMessage{}
+	for _, name := range names {
+		data, err := fs.ReadFile(s.fixtures, path.Join("info", name+".json"))
+		if err != nil {
+			continue
+		}
+		results = append(results, json.RawMessage(data))
+	}
+	writeJSON(w, response{Type: "multiinfo", Version: 5, Results: results})
+}
+
+// suggest responde, como o aurweb, com um array JSON de até 20 nomes (dos
+// pacotes em info/) que começam com arg
+func (s *Server) suggest(w http.ResponseWriter, arg string) {
+	names := []string{}
+	entries, _ := fs.ReadDir(s.fixtures, "info")
+	for _, entry := range entries {
+		name := strings.TrimSuffix(entry.Name(), ".json")
+		if strings.HasPrefix(name, arg) && len(names) < 20 {
+			names = append(names, name)
+		}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(names)
+}
+
+type response struct {
+	ResultCount int               `json:"resultcount"`
+	Results     []json.RawMessage `json:"results"`
+	Type        string            `json:"type"`
+	Version     int               `json:"version"`
+	Error       string            `json:"error,omitempty"`
+}
+
+func writeError(w http.ResponseWriter, msg string) {
+	writeJSON(w, response{Type: "error", Version: 5, Results: []json.RawMessage{}, Error: msg})
+}
+
+func writeJSON(w http.ResponseWriter, resp response) {
+	resp.ResultCount = len(resp.Results)
+	data, err := json.Marshal(resp)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(data)
+}
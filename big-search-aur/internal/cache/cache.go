@@ -0,0 +1,331 @@
+/*
+  cache.go - cache persistente em disco para o big-search-aur
+    Chili GNU/Linux - https://github.com/vcatafesta/chili/go
+    Chili GNU/Linux - https://chililinux.com
+    Chili GNU/Linux - https://chilios.com.br
+
+  Created: 2026/10/17
+  Altered: 2026/10/17
+
+  Copyright (c) 2024-2026, Vilmar Catafesta <vcatafesta@gmail.com>
+  All rights reserved.
+
+  Redistribution and use in source and binary forms, with or without
+  modification, are permitted provided that the following conditions
+  are met:
+  1. Redistributions of source code must retain the above copyright
+    notice, this list of conditions and the following disclaimer.
+  2. Redistributions in binary form must reproduce the above copyright
+    notice, this list of conditions and the following disclaimer in the
+    documentation and/or other materials provided with the distribution.
+
+  THIS SOFTWARE IS PROVIDED BY THE AUTHOR ``AS IS'' AND ANY EXPRESS OR
+  IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED WARRANTIES
+  OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE DISCLAIMED.
+  IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR ANY DIRECT, INDIRECT,
+  INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING, BUT
+  NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE,
+  DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY
+  THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT
+  (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF
+  THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+*/
+
+// Package cache guarda as respostas do AUR em disco, para que várias
+// execuções do big-search-aur (inclusive simultâneas) compartilhem os
+// mesmos resultados enquanto o TTL não expirar.
+//
+// Cada entrada é um arquivo JSON gravado de forma atômica (arquivo
+// temporário + rename), de modo que um leitor nunca vê uma entrada pela
+// metade. A limpeza por tamanho e os contadores de acertos e falhas, guardados
+// em "<dir>/.stats", usam um flock exclusivo em "<dir>/.lock".
+package cache
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"syscall"
+	"time"
+)
+
+const (
+	entryExt  = ".json"
+	lockName  = ".lock"
+	statsName = ".stats"
+)
+
+// Cache representa um diretório de cache em disco.
+type Cache struct {
+	Dir        string        // diretório das entradas
+	TTL        time.Duration // validade de cada entrada
+	MaxBytes   int64         // tamanho máximo do diretório (0 = sem limite)
+	MaxEntries int           // número máximo de entradas (0 = sem limite)
+}
+
+// Entry é o conteúdo de um arquivo de cache.
+type Entry struct {
+	Endpoint string          `json:"endpoint"`
+	Mode     string          `json:"mode"`
+	Term     string          `json:"term"`
+	By       string          `json:"by"`
+	Created  time.Time       `json:"created"`
+	Data     json.RawMessage `json:"data"`
+}
+
+// Stats resume o estado do cache para o --cache-stats.
+type Stats struct {
+	Dir        string     `json:"dir"`
+	TTL        string     `json:"ttl"`
+	MaxBytes   int64      `json:"max_bytes"`
+	MaxEntries int        `json:"max_entries"`
+	Entries    int        `json:"entries"`
+	Expired    int        `json:"expired"`
+	Bytes      int64      `json:"bytes"`
+	Oldest     *time.Time `json:"oldest,omitempty"`
+	Newest     *time.Time `json:"newest,omitempty"`
+	Hits       int64      `json:"hits"`
+	Misses     int64      `json:"misses"`
+}
+
+// DefaultDir retorna $XDG_CACHE_HOME/<app>, ou ~/.cache/<app> se a variável
+// não estiver definida.
+func DefaultDir(app string) string {
+	if dir := os.Getenv("XDG_CACHE_HOME"); dir != "" {
+		return filepath.Join(dir, app)
+	}
+	if home, err := os.UserHomeDir(); err == nil {
+		return filepath.Join(home, ".cache", app)
+	}
+	return filepath.Join(os.TempDir(), app)
+}
+
+// Key gera o nome da entrada para a tupla (endpoint, modo, termo, campo by).
+// O endpoint faz parte da chave para que um --aur-url diferente não receba
+// respostas gravadas a partir de outro servidor.
+func Key(endpoint, mode, term, by string) string {
+	sum := sha256.Sum256([]byte(endpoint + "\x00" + mode + "\x00" + term + "\x00" + by))
+	return hex.EncodeToString(sum[:])
+}
+
+// Get retorna a entrada da chave se ela existir e ainda estiver dentro do TTL.
+func (c *Cache) Get(key string) (*Entry, bool) {
+	data, err := os.ReadFile(c.path(key))
+	if err != nil {
+		c.count(false)
+		return nil, false
+	}
+	var entry Entry
+	if err := json.Unmarshal(data, &entry); err != nil || c.expired(entry.Created) {
+		c.count(false)
+		return nil, false
+	}
+	c.count(true)
+	return &entry, true
+}
+
+// Put grava a entrada de forma atômica e aplica os limites de tamanho.
+func (c *Cache) Put(key string, entry Entry) error {
+	if err := os.MkdirAll(c.Dir, 0o755); err != nil {
+		return err
+	}
+	if entry.Created.IsZero() {
+		entry.Created = time.Now()
+	}
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(c.Dir, ".tmp-*")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := os.Rename(tmp.Name(), c.path(key)); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return c.prune()
+}
+
+// Stats percorre o diretório e devolve um resumo do cache.
+func (c *Cache) Stats() (Stats, error) {
+	st := Stats{
+		Dir:        c.Dir,
+		TTL:        c.TTL.String(),
+		MaxBytes:   c.MaxBytes,
+		MaxEntries: c.MaxEntries,
+	}
+	files, err := c.entries()
+	if err != nil {
+		return st, err
+	}
+	for _, f := range files {
+		st.Entries++
+		st.Bytes += f.size
+		if c.expired(f.mtime) {
+			st.Expired++
+		}
+		if st.Oldest == nil || f.mtime.Before(*st.Oldest) {
+			st.Oldest = &f.mtime
+		}
+		if st.Newest == nil || f.mtime.After(*st.Newest) {
+			st.Newest = &f.mtime
+		}
+	}
+	// Sem o diretório não há contadores, e o withLock o criaria
+	if _, err := os.Stat(c.Dir); err == nil {
+		c.withLock(func() error {
+			n := c.counters()
+			st.Hits, st.Misses = n.Hits, n.Misses
+			return nil
+		})
+	}
+	return st, nil
+}
+
+func (c *Cache) path(key string) string {
+	return filepath.Join(c.Dir, key+entryExt)
+}
+
+func (c *Cache) expired(created time.Time) bool {
+	return c.TTL > 0 && time.Since(created) > c.TTL
+}
+
+type fileInfo struct {
+	path  string
+	size  int64
+	mtime time.Time
+}
+
+func (c *Cache) entries() ([]fileInfo, error) {
+	dirEntries, err := os.ReadDir(c.Dir)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var files []fileInfo
+	for _, de := range dirEntries {
+		name := de.Name()
+		if de.IsDir() || strings.HasPrefix(name, ".") || !strings.HasSuffix(name, entryExt) {
+			continue
+		}
+		info, err := de.Info()
+		if err != nil {
+			// Outro processo pode ter removido a entrada neste meio tempo
+			continue
+		}
+		files = append(files, fileInfo{
+			path:  filepath.Join(c.Dir, name),
+			size:  info.Size(),
+			mtime: info.ModTime(),
+		})
+	}
+	return files, nil
+}
+
+// prune remove entradas expiradas e, se os limites forem ultrapassados, as
+// mais antigas primeiro.
+func (c *Cache) prune() error {
+	if c.MaxBytes <= 0 && c.MaxEntries <= 0 && c.TTL <= 0 {
+		return nil
+	}
+	return c.withLock(func() error {
+		files, err := c.entries()
+		if err != nil {
+			return err
+		}
+		sort.Slice(files, func(i, j int) bool { return files[i].mtime.Before(files[j].mtime) })
+
+		var total int64
+		for _, f := range files {
+			total += f.size
+		}
+		n := len(files)
+		for _, f := range files {
+			over := (c.MaxBytes > 0 && total > c.MaxBytes) || (c.MaxEntries > 0 && n > c.MaxEntries)
+			if !over && !c.expired(f.mtime) {
+				continue
+			}
+			if err := os.Remove(f.path); err != nil && !errors.Is(err, os.ErrNotExist) {
+				return err
+			}
+			total -= f.size
+			n--
+		}
+		return nil
+	})
+}
+
+// counters é o conteúdo de "<dir>/.stats".
+type counters struct {
+	Hits   int64 `json:"hits"`
+	Misses int64 `json:"misses"`
+}
+
+// count incrementa o contador de acertos ou falhas. A leitura e a regravação
+// do arquivo acontecem sob o flock, de modo que processos simultâneos não
+// perdem incrementos e o arquivo tem sempre o mesmo tamanho. Erros são
+// ignorados: as estatísticas não devem impedir uma busca.
+func (c *Cache) count(hit bool) {
+	// Só cria o contador se o diretório do cache já existir
+	if _, err := os.Stat(c.Dir); err != nil {
+		return
+	}
+	c.withLock(func() error {
+		n := c.counters()
+		if hit {
+			n.Hits++
+		} else {
+			n.Misses++
+		}
+		data, err := json.Marshal(n)
+		if err != nil {
+			return err
+		}
+		return os.WriteFile(filepath.Join(c.Dir, statsName), data, 0o644)
+	})
+}
+
+// counters lê os contadores; deve ser chamada com o flock. Um arquivo
+// ausente ou ilegível conta como zero.
+func (c *Cache) counters() counters {
+	var n counters
+	if data, err := os.ReadFile(filepath.Join(c.Dir, statsName)); err == nil {
+		json.Unmarshal(data, &n)
+	}
+	return n
+}
+
+// withLock executa fn com um flock exclusivo no diretório do cache.
+func (c *Cache) withLock(fn func() error) error {
+	if err := os.MkdirAll(c.Dir, 0o755); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(filepath.Join(c.Dir, lockName), os.O_CREATE|os.O_RDWR, 0o644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	if err := syscall.Flock(int(f.Fd()), syscall.LOCK_EX); err != nil {
+		return err
+	}
+	defer syscall.Flock(int(f.Fd()), syscall.LOCK_UN)
+	return fn()
+}
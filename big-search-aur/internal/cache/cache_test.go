@@ -0,0 +1,167 @@
+package cache
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+	"testing"
+	"time"
+)
+
+const aurRPC = "https://aur.archlinux.org/rpc"
+
+func TestGetPut(t *testing.T) {
+	c := &Cache{Dir: t.TempDir(), TTL: time.Hour}
+	key := Key(aurRPC, "search", "yay", "name")
+
+	if _, found := c.Get(key); found {
+		t.Fatal("entrada encontrada num cache vazio")
+	}
+	data := json.RawMessage(`[{"Name":"yay"}]`)
+	if err := c.Put(key, Entry{Mode: "search", Term: "yay", By: "name", Data: data}); err != nil {
+		t.Fatal(err)
+	}
+	entry, found := c.Get(key)
+	if !found {
+		t.Fatal("entrada gravada não encontrada")
+	}
+	if entry.Mode != "search" || entry.Term != "yay" || entry.By != "name" || string(entry.Data) != string(data) {
+		t.Errorf("entrada lida difere da gravada: %+v", entry)
+	}
+	if entry.Created.IsZero() {
+		t.Error("Put não preencheu Created")
+	}
+
+	st, err := c.Stats()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if st.Entries != 1 || st.Hits != 1 || st.Misses != 1 {
+		t.Errorf("Stats = %+v, esperado 1 entrada, 1 acerto e 1 falha", st)
+	}
+	if st.Oldest == nil || st.Newest == nil {
+		t.Error("Oldest/Newest não preenchidos")
+	}
+}
+
+func TestKey(t *testing.T) {
+	keys := map[string]bool{}
+	for _, k := range []string{
+		Key(aurRPC, "search", "yay", "name"),
+		Key(aurRPC, "search", "yay", "maintainer"),
+		Key(aurRPC, "info", "yay", "name"),
+		Key(aurRPC, "search", "yay\x00", "name"),
+		Key("http://127.0.0.1:8080/rpc", "search", "yay", "name"),
+	} {
+		if keys[k] {
+			t.Errorf("chave repetida: %s", k)
+		}
+		keys[k] = true
+	}
+}
+
+func TestTTL(t *testing.T) {
+	c := &Cache{Dir: t.TempDir(), TTL: time.Minute}
+	key := Key(aurRPC, "info", "old", "")
+	if err := c.Put(key, Entry{Mode: "info", Term: "old", Created: time.Now().Add(-time.Hour)}); err != nil {
+		t.Fatal(err)
+	}
+	if _, found := c.Get(key); found {
+		t.Error("entrada expirada devolvida por Get")
+	}
+
+	c.TTL = 0
+	if _, found := c.Get(key); !found {
+		t.Error("com TTL 0 a entrada não deveria expirar")
+	}
+}
+
+func TestPrune(t *testing.T) {
+	c := &Cache{Dir: t.TempDir(), TTL: time.Hour, MaxEntries: 3}
+	base := time.Now().Add(-time.Minute)
+	for i := range 5 {
+		key := Key(aurRPC, "info", fmt.Sprint(i), "")
+		if err := c.Put(key, Entry{Mode: "info", Term: fmt.Sprint(i)}); err != nil {
+			t.Fatal(err)
+		}
+		// O prune ordena pela data de modificação; a resolução do relógio
+		// do sistema de arquivos não garante ordem entre gravações seguidas
+		mtime := base.Add(time.Duration(i) * time.Second)
+		os.Chtimes(c.path(key), mtime, mtime)
+	}
+	if err := c.prune(); err != nil {
+		t.Fatal(err)
+	}
+
+	for i := range 5 {
+		_, err := os.Stat(c.path(Key(aurRPC, "info", fmt.Sprint(i), "")))
+		if kept := err == nil; kept != (i >= 2) {
+			t.Errorf("entrada %d: mantida = %v", i, kept)
+		}
+	}
+
+	// Entradas expiradas saem mesmo abaixo dos limites
+	c.MaxEntries = 0
+	old := time.Now().Add(-2 * time.Hour)
+	os.Chtimes(c.path(Key(aurRPC, "info", "2", "")), old, old)
+	if err := c.prune(); err != nil {
+		t.Fatal(err)
+	}
+	if st, _ := c.Stats(); st.Entries != 2 {
+		t.Errorf("após remover a expirada: %d entradas, esperado 2", st.Entries)
+	}
+
+	c.MaxBytes = 1
+	if err := c.prune(); err != nil {
+		t.Fatal(err)
+	}
+	if st, _ := c.Stats(); st.Entries != 0 {
+		t.Errorf("com MaxBytes 1: %d entradas, esperado 0", st.Entries)
+	}
+}
+
+// TestConcurrent simula várias execuções do big-search-aur gravando e lendo
+// o mesmo diretório ao mesmo tempo.
+func TestConcurrent(t *testing.T) {
+	dir := t.TempDir()
+	const writers, rounds = 8, 25
+
+	var wg sync.WaitGroup
+	for w := range writers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			// Cada goroutine usa o seu próprio Cache, como processos distintos
+			c := &Cache{Dir: dir, TTL: time.Hour, MaxEntries: 10}
+			for r := range rounds {
+				key := Key(aurRPC, "search", fmt.Sprint(r%4), "")
+				data := json.RawMessage(fmt.Sprintf(`{"writer":%d,"round":%d}`, w, r))
+				if err := c.Put(key, Entry{Mode: "search", Term: fmt.Sprint(r % 4), Data: data}); err != nil {
+					t.Error(err)
+					return
+				}
+				if entry, found := c.Get(key); found && !json.Valid(entry.Data) {
+					t.Errorf("entrada pela metade: %q", entry.Data)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	c := &Cache{Dir: dir, TTL: time.Hour}
+	st, err := c.Stats()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if st.Entries != 4 {
+		t.Errorf("%d entradas, esperado 4", st.Entries)
+	}
+	if got := st.Hits + st.Misses; got != writers*rounds {
+		t.Errorf("acertos+falhas = %d, esperado %d", got, writers*rounds)
+	}
+	if leftovers, _ := os.ReadDir(dir); len(leftovers) > 4+2 {
+		// 4 entradas + .lock e .stats: nenhum .tmp-* esquecido
+		t.Errorf("arquivos temporários sobraram: %d arquivos no diretório", len(leftovers))
+	}
+}
@@ -0,0 +1,235 @@
+/*
+  depgraph.go - grafo de dependências e ordem de compilação
+    Chili GNU/Linux - https://github.com/vcatafesta/chili/go
+    Chili GNU/Linux - https://chililinux.com
+    Chili GNU/Linux - https://chilios.com.br
+
+  Created: 2026/10/17
+  Altered: 2026/10/17
+
+  Copyright (c) 2024-2026, Vilmar Catafesta <vcatafesta@gmail.com>
+  All rights reserved.
+
+  Redistribution and use in source and binary forms, with or without
+  modification, are permitted provided that the following conditions
+  are met:
+  1. Redistributions of source code must retain the above copyright
+    notice, this list of conditions and the following disclaimer.
+  2. Redistributions in binary form must reproduce the above copyright
+    notice, this list of conditions and the following disclaimer in the
+    documentation and/or other materials provided with the distribution.
+
+  THIS SOFTWARE IS PROVIDED BY THE AUTHOR ``AS IS'' AND ANY EXPRESS OR
+  IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED WARRANTIES
+  OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE DISCLAIMED.
+  IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR ANY DIRECT, INDIRECT,
+  INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING, BUT
+  NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE,
+  DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY
+  THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT
+  (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF
+  THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+*/
+
+// Package depgraph mantém o grafo "pacote depende de pacote" usado pelo
+// --deps-tree e calcula a ordem de compilação (dependências primeiro),
+// apontando os ciclos que impedem essa ordem.
+package depgraph
+
+import "sort"
+
+// Graph é um grafo dirigido: uma aresta a -> b significa que a depende de b.
+type Graph struct {
+	nodes []string
+	edges map[string][]string
+}
+
+// New retorna um grafo vazio
+func New() *Graph {
+	return &Graph{edges: make(map[string][]string)}
+}
+
+// AddNode inclui o nó, se ainda não existir
+func (g *Graph) AddNode(name string) {
+	if _, ok := g.edges[name]; ok {
+		return
+	}
+	g.edges[name] = nil
+	g.nodes = append(g.nodes, name)
+}
+
+// AddEdge registra que from depende de to, incluindo os nós se necessário
+func (g *Graph) AddEdge(from, to string) {
+	g.AddNode(from)
+	g.AddNode(to)
+	for _, dep := range g.edges[from] {
+		if dep == to {
+			return
+		}
+	}
+	g.edges[from] = append(g.edges[from], to)
+}
+
+// Nodes retorna os nós na ordem em que foram incluídos
+func (g *Graph) Nodes() []string {
+	return append([]string(nil), g.nodes...)
+}
+
+// Edges retorna as dependências diretas do nó, na ordem em que foram incluídas
+func (g *Graph) Edges(name string) []string {
+	return append([]string(nil), g.edges[name]...)
+}
+
+// Order retorna a ordem topológica (cada nó depois das suas dependências,
+// empates resolvidos pelo nome) e os ciclos encontrados. Os nós que estão em
+// um ciclo, ou que dependem de um, ficam fora da ordem.
+//
+// Cada ciclo começa e termina no mesmo nó, ex: [a b a].
+func (g *Graph) Order() (order []string, cycles [][]string) {
+	pending := make(map[string]int, len(g.nodes)) // dependências ainda não ordenadas
+	dependents := make(map[string][]string, len(g.nodes))
+	for _, name := range g.nodes {
+		pending[name] = len(g.edges[name])
+		for _, dep := range g.edges[name] {
+			dependents[dep] = append(dependents[dep], name)
+		}
+	}
+
+	var ready []string
+	for _, name := range g.nodes {
+		if pending[name] == 0 {
+			ready = append(ready, name)
+		}
+	}
+	for len(ready) > 0 {
+		sort.Strings(ready)
+		name := ready[0]
+		ready = ready[1:]
+		order = append(order, name)
+		for _, dependent := range dependents[name] {
+			pending[dependent]--
+			if pending[dependent] == 0 {
+				ready = append(ready, dependent)
+			}
+		}
+	}
+
+	if len(order) < len(g.nodes) {
+		cycles = g.cycles(pending)
+	}
+	return order, cycles
+}
+
+// cycles procura os componentes fortemente conexos (Tarjan) entre os nós que
+// sobraram da ordenação e devolve um ciclo concreto de cada um
+func (g *Graph) cycles(pending map[string]int) [][]string {
+	var (
+		index   = make(map[string]int)
+		lowlink = make(map[string]int)
+		onStack = make(map[string]bool)
+		stack   []string
+		counter int
+		result  [][]string
+	)
+
+	var connect func(name string)
+	connect = func(name string) {
+		index[name] = counter
+		lowlink[name] = counter
+		counter++
+		stack = append(stack, name)
+		onStack[name] = true
+
+		for _, dep := range g.edges[name] {
+			if pending[dep] == 0 {
+				continue // já ordenado, não participa de ciclo
+			}
+			if _, visited := index[dep]; !visited {
+				connect(dep)
+				lowlink[name] = min(lowlink[name], lowlink[dep])
+			} else if onStack[dep] {
+				lowlink[name] = min(lowlink[name], index[dep])
+			}
+		}
+
+		if lowlink[name] != index[name] {
+			return
+		}
+		component := make(map[string]bool)
+		for {
+			top := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			onStack[top] = false
+			component[top] = true
+			if top == name {
+				break
+			}
+		}
+		if len(component) > 1 || g.hasEdge(name, name) {
+			result = append(result, g.cycleIn(component))
+		}
+	}
+
+	names := make([]string, 0, len(pending))
+	for name, n := range pending {
+		if n > 0 {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if _, visited := index[name]; !visited {
+			connect(name)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i][0] < result[j][0] })
+	return result
+}
+
+// cycleIn retorna o menor ciclo que sai do primeiro nó (em ordem alfabética)
+// do componente e volta a ele, andando só por nós do componente
+func (g *Graph) cycleIn(component map[string]bool) []string {
+	var start string
+	for name := range component {
+		if start == "" || name < start {
+			start = name
+		}
+	}
+	if g.hasEdge(start, start) {
+		return []string{start, start}
+	}
+
+	parent := map[string]string{start: ""}
+	queue := []string{start}
+	for len(queue) > 0 {
+		name := queue[0]
+		queue = queue[1:]
+		for _, dep := range g.edges[name] {
+			if dep == start {
+				// Caminho de volta até start, depois invertido
+				cycle := []string{start}
+				for n := name; n != ""; n = parent[n] {
+					cycle = append(cycle, n)
+				}
+				for i, j := 0, len(cycle)-1; i < j; i, j = i+1, j-1 {
+					cycle[i], cycle[j] = cycle[j], cycle[i]
+				}
+				return cycle
+			}
+			if _, seen := parent[dep]; !seen && component[dep] {
+				parent[dep] = name
+				queue = append(queue, dep)
+			}
+		}
+	}
+	return []string{start, start}
+}
+
+func (g *Graph) hasEdge(from, to string) bool {
+	for _, dep := range g.edges[from] {
+		if dep == to {
+			return true
+		}
+	}
+	return false
+}
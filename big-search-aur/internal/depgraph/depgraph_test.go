@@ -0,0 +1,126 @@
+package depgraph
+
+import (
+	"reflect"
+	"testing"
+)
+
+// build monta o grafo a partir de pares "de -> para"
+func build(edges ...[2]string) *Graph {
+	g := New()
+	for _, e := range edges {
+		g.AddEdge(e[0], e[1])
+	}
+	return g
+}
+
+func TestOrder(t *testing.T) {
+	cases := []struct {
+		name   string
+		graph  *Graph
+		order  []string
+		cycles [][]string
+	}{
+		{
+			name:  "diamante",
+			graph: build([2]string{"a", "b"}, [2]string{"a", "c"}, [2]string{"b", "d"}, [2]string{"c", "d"}),
+			order: []string{"d", "b", "c", "a"},
+		},
+		{
+			name: "laço no próprio nó",
+			graph: func() *Graph {
+				g := build([2]string{"x", "x"}, [2]string{"y", "x"})
+				g.AddNode("z")
+				return g
+			}(),
+			order:  []string{"z"},
+			cycles: [][]string{{"x", "x"}},
+		},
+		{
+			name: "dois ciclos separados",
+			graph: build(
+				[2]string{"a", "b"}, [2]string{"b", "a"},
+				[2]string{"c", "d"}, [2]string{"d", "e"}, [2]string{"e", "c"},
+				[2]string{"f", "a"}, // depende de um ciclo, fica fora da ordem
+				[2]string{"g", "h"},
+			),
+			order:  []string{"h", "g"},
+			cycles: [][]string{{"a", "b", "a"}, {"c", "d", "e", "c"}},
+		},
+		{
+			name:  "arestas repetidas",
+			graph: build([2]string{"a", "b"}, [2]string{"a", "b"}),
+			order: []string{"b", "a"},
+		},
+	}
+	for _, c := range cases {
+		order, cycles := c.graph.Order()
+		if !reflect.DeepEqual(order, c.order) {
+			t.Errorf("%s: ordem %v, esperado %v", c.name, order, c.order)
+		}
+		if !reflect.DeepEqual(cycles, c.cycles) {
+			t.Errorf("%s: ciclos %v, esperado %v", c.name, cycles, c.cycles)
+		}
+	}
+}
+
+func TestCycles(t *testing.T) {
+	g := build(
+		[2]string{"c", "d"}, [2]string{"d", "c"},
+		[2]string{"a", "a"},
+		[2]string{"b", "c"},
+	)
+	// b depende do ciclo c-d, mas não está em nenhum
+	pending := map[string]int{"a": 1, "b": 1, "c": 1, "d": 1}
+	want := [][]string{{"a", "a"}, {"c", "d", "c"}}
+	if got := g.cycles(pending); !reflect.DeepEqual(got, want) {
+		t.Errorf("cycles = %v, esperado %v", got, want)
+	}
+
+	// Nós já ordenados (pending 0) não entram nos componentes
+	g.AddEdge("d", "e")
+	pending["e"] = 0
+	if got := g.cycles(pending); !reflect.DeepEqual(got, want) {
+		t.Errorf("cycles com nó ordenado = %v, esperado %v", got, want)
+	}
+}
+
+func TestCycleIn(t *testing.T) {
+	cases := []struct {
+		name      string
+		graph     *Graph
+		component []string
+		want      []string
+	}{
+		{
+			name:      "laço no próprio nó",
+			graph:     build([2]string{"a", "a"}, [2]string{"a", "b"}, [2]string{"b", "a"}),
+			component: []string{"a", "b"},
+			want:      []string{"a", "a"},
+		},
+		{
+			name:      "menor ciclo a partir do primeiro nó",
+			graph:     build([2]string{"a", "b"}, [2]string{"b", "c"}, [2]string{"c", "a"}, [2]string{"a", "c"}),
+			component: []string{"c", "b", "a"},
+			want:      []string{"a", "c", "a"},
+		},
+		{
+			name: "não sai do componente",
+			graph: build(
+				[2]string{"b", "x"}, [2]string{"x", "b"}, // atalho por fora do componente
+				[2]string{"b", "c"}, [2]string{"c", "d"}, [2]string{"d", "b"},
+			),
+			component: []string{"b", "c", "d"},
+			want:      []string{"b", "c", "d", "b"},
+		},
+	}
+	for _, c := range cases {
+		component := make(map[string]bool)
+		for _, name := range c.component {
+			component[name] = true
+		}
+		if got := c.graph.cycleIn(component); !reflect.DeepEqual(got, c.want) {
+			t.Errorf("%s: cycleIn = %v, esperado %v", c.name, got, c.want)
+		}
+	}
+}
@@ -0,0 +1,247 @@
+/*
+  i18n.go - catálogos de mensagens (gettext .po) do big-search-aur
+    Chili GNU/Linux - https://github.com/vcatafesta/chili/go
+    Chili GNU/Linux - https://chililinux.com
+    Chili GNU/Linux - https://chilios.com.br
+
+  Created: 2026/10/17
+  Altered: 2026/10/17
+
+  Copyright (c) 2024-2026, Vilmar Catafesta <vcatafesta@gmail.com>
+  All rights reserved.
+
+  Redistribution and use in source and binary forms, with or without
+  modification, are permitted provided that the following conditions
+  are met:
+  1. Redistributions of source code must retain the above copyright
+    notice, this list of conditions and the following disclaimer.
+  2. Redistributions in binary form must reproduce the above copyright
+    notice, this list of conditions and the following disclaimer in the
+    documentation and/or other materials provided with the distribution.
+
+  THIS SOFTWARE IS PROVIDED BY THE AUTHOR ``AS IS'' AND ANY EXPRESS OR
+  IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED WARRANTIES
+  OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE DISCLAIMED.
+  IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR ANY DIRECT, INDIRECT,
+  INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING, BUT
+  NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE,
+  DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY
+  THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT
+  (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF
+  THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+*/
+
+// Package i18n traduz as mensagens do big-search-aur usando catálogos no
+// formato .po do gettext, embutidos no binário (po/<idioma>.po).
+//
+// As mensagens do código são os msgid, em português, sempre passados como
+// literais para gettext("..."), de modo que o catálogo pode ser extraído como
+// o chili-tradutor-go faz com os scripts shell:
+//
+//	xgettext --from-code=UTF-8 --language=C --keyword=gettext -o big-search-aur.pot big-search-aur.go internal/*/*.go
+//
+// Os pacotes em internal/ declaram o seu próprio "var gettext = i18n.Gettext"
+// para que a mesma palavra-chave sirva em todos os arquivos.
+//
+// O idioma vem da primeira variável definida entre LC_ALL, LC_MESSAGES e
+// LANG. Os locales C e POSIX mostram os msgid sem tradução, e um idioma sem
+// catálogo usa o inglês.
+package i18n
+
+import (
+	"bufio"
+	"bytes"
+	"embed"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+//go:embed po/*.po
+var catalogs embed.FS
+
+// Catálogo usado quando o idioma do locale não tem um .po
+const fallbackLanguage = "en"
+
+var (
+	active   atomic.Pointer[map[string]string]
+	loadOnce sync.Once
+)
+
+// Locale retorna o locale das mensagens: LC_ALL, LC_MESSAGES ou LANG, o
+// primeiro que estiver definido.
+func Locale() string {
+	for _, name := range []string{"LC_ALL", "LC_MESSAGES", "LANG"} {
+		if value := os.Getenv(name); value != "" {
+			return value
+		}
+	}
+	return ""
+}
+
+// Languages retorna os idiomas com catálogo embutido, ex: [en pt_BR].
+func Languages() []string {
+	entries, _ := catalogs.ReadDir("po")
+	var languages []string
+	for _, entry := range entries {
+		languages = append(languages, strings.TrimSuffix(entry.Name(), ".po"))
+	}
+	return languages
+}
+
+// Language escolhe o catálogo para o locale: "pt_BR.UTF-8" usa pt_BR, "pt_PT"
+// também (mesma língua), "en_US" usa en e "de_DE" cai no inglês. Para C,
+// POSIX ou locale vazio retorna "", sem tradução.
+func Language(locale string) string {
+	locale, _, _ = strings.Cut(locale, ".")
+	locale, _, _ = strings.Cut(locale, "@")
+	if locale == "" || locale == "C" || locale == "POSIX" {
+		return ""
+	}
+	lang, _, _ := strings.Cut(locale, "_")
+	var sameLang string
+	for _, available := range Languages() {
+		if available == locale {
+			return available
+		}
+		if prefix, _, _ := strings.Cut(available, "_"); prefix == lang && sameLang == "" {
+			sameLang = available
+		}
+	}
+	if sameLang != "" {
+		return sameLang
+	}
+	return fallbackLanguage
+}
+
+// SetLanguage ativa o catálogo do idioma; "" desliga a tradução.
+func SetLanguage(lang string) error {
+	loadOnce.Do(func() {})
+	messages, err := load(lang)
+	if err != nil {
+		return err
+	}
+	active.Store(&messages)
+	return nil
+}
+
+// Gettext retorna a tradução de msgid, ou o próprio msgid quando o catálogo
+// não tem a mensagem. Na primeira chamada carrega o catálogo do locale.
+func Gettext(msgid string) string {
+	loadOnce.Do(func() {
+		messages, _ := load(Language(Locale()))
+		active.Store(&messages)
+	})
+	if msgstr, ok := (*active.Load())[msgid]; ok {
+		return msgstr
+	}
+	return msgid
+}
+
+func load(lang string) (map[string]string, error) {
+	if lang == "" {
+		return map[string]string{}, nil
+	}
+	data, err := catalogs.ReadFile(path.Join("po", lang+".po"))
+	if err != nil {
+		return map[string]string{}, fmt.Errorf("idioma sem catálogo: %s", lang)
+	}
+	messages, err := Parse(bytes.NewReader(data))
+	if err != nil {
+		return map[string]string{}, fmt.Errorf("%s.po: %w", lang, err)
+	}
+	return messages, nil
+}
+
+// Parse lê um arquivo .po e retorna o mapa msgid -> msgstr. Entradas sem
+// tradução, marcadas como fuzzy ou o cabeçalho (msgid "") ficam de fora;
+// entradas com msgctxt usam a chave "contexto\x04msgid", como o gettext, e
+// nas formas plurais vale o msgstr[0].
+func Parse(r io.Reader) (map[string]string, error) {
+	messages := map[string]string{}
+	var msgctxt, msgid, msgstr *string
+	var current *string
+	var fuzzy bool
+
+	flush := func() {
+		if msgid != nil && msgstr != nil && *msgid != "" && *msgstr != "" && !fuzzy {
+			key := *msgid
+			if msgctxt != nil {
+				key = *msgctxt + "\x04" + key
+			}
+			messages[key] = *msgstr
+		}
+		msgctxt, msgid, msgstr, current, fuzzy = nil, nil, nil, nil, false
+	}
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+		line := strings.TrimSpace(scanner.Text())
+		switch {
+		case line == "":
+			flush()
+			continue
+		case strings.HasPrefix(line, "#,"):
+			if msgid != nil {
+				flush()
+			}
+			fuzzy = fuzzy || strings.Contains(line, "fuzzy")
+			continue
+		case strings.HasPrefix(line, "#"):
+			continue
+		}
+
+		keyword, quoted, found := strings.Cut(line, " ")
+		if strings.HasPrefix(line, `"`) {
+			keyword, quoted, found = "", line, true
+		}
+		if !found {
+			return nil, fmt.Errorf("linha %d: esperado uma string entre aspas", lineNumber)
+		}
+		value, err := strconv.Unquote(strings.TrimSpace(quoted))
+		if err != nil {
+			return nil, fmt.Errorf("linha %d: string inválida %s", lineNumber, quoted)
+		}
+
+		switch keyword {
+		case "":
+			if current == nil {
+				return nil, fmt.Errorf("linha %d: continuação sem msgid ou msgstr", lineNumber)
+			}
+			*current += value
+		case "msgctxt":
+			if msgid != nil {
+				flush()
+			}
+			msgctxt, current = &value, &value
+		case "msgid":
+			if msgid != nil {
+				flush()
+			}
+			msgid, current = &value, &value
+		case "msgid_plural":
+			current = new(string) // o plural não entra no mapa
+		case "msgstr", "msgstr[0]":
+			if msgid == nil {
+				return nil, fmt.Errorf("linha %d: msgstr sem msgid", lineNumber)
+			}
+			msgstr, current = &value, &value
+		default:
+			if !strings.HasPrefix(keyword, "msgstr[") {
+				return nil, fmt.Errorf("linha %d: palavra-chave desconhecida %s", lineNumber, keyword)
+			}
+			current = new(string)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	flush()
+	return messages, nil
+}
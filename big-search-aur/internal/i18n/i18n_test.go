@@ -0,0 +1,114 @@
+package i18n
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestLanguage(t *testing.T) {
+	cases := map[string]string{
+		"":            "",
+		"C":           "",
+		"C.UTF-8":     "",
+		"POSIX":       "",
+		"pt_BR.UTF-8": "pt_BR",
+		"pt_PT":       "pt_BR",
+		"en_US.UTF-8": "en",
+		"en_GB@euro":  "en",
+		"de_DE.UTF-8": "en",
+	}
+	for locale, want := range cases {
+		if got := Language(locale); got != want {
+			t.Errorf("Language(%q) = %q, esperado %q", locale, got, want)
+		}
+	}
+}
+
+func TestLocale(t *testing.T) {
+	t.Setenv("LC_ALL", "")
+	t.Setenv("LC_MESSAGES", "")
+	t.Setenv("LANG", "pt_BR.UTF-8")
+	if got := Locale(); got != "pt_BR.UTF-8" {
+		t.Errorf("só LANG: %q", got)
+	}
+	t.Setenv("LC_MESSAGES", "en_US.UTF-8")
+	if got := Locale(); got != "en_US.UTF-8" {
+		t.Errorf("LC_MESSAGES antes do LANG: %q", got)
+	}
+	t.Setenv("LC_ALL", "C")
+	if got := Locale(); got != "C" {
+		t.Errorf("LC_ALL antes de todos: %q", got)
+	}
+}
+
+func TestParse(t *testing.T) {
+	po := `# comentário
+msgid ""
+msgstr ""
+"Language: en\n"
+
+#: big-search-aur.go
+msgid "Erro: %s requer um argumento"
+msgstr "Error: %s requires "
+"an argument"
+
+msgid "sem tradução"
+msgstr ""
+
+#, fuzzy
+msgid "duvidosa"
+msgstr "doubtful"
+
+msgctxt "menu"
+msgid "Sair"
+msgstr "Quit"
+
+msgid "linha\tcom \"aspas\"\n"
+msgstr "line\twith \"quotes\"\n"
+`
+	messages, err := Parse(strings.NewReader(po))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]string{
+		"Erro: %s requer um argumento": "Error: %s requires an argument",
+		"menu\x04Sair":                 "Quit",
+		"linha\tcom \"aspas\"\n":       "line\twith \"quotes\"\n",
+	}
+	if len(messages) != len(want) {
+		t.Errorf("mensagens: %q", messages)
+	}
+	for msgid, msgstr := range want {
+		if messages[msgid] != msgstr {
+			t.Errorf("%q = %q, esperado %q", msgid, messages[msgid], msgstr)
+		}
+	}
+
+	for _, bad := range []string{"msgstr \"sem msgid\"\n", "msgid sem-aspas\n", "\"continuação solta\"\n", "msgfoo \"x\"\n"} {
+		if _, err := Parse(strings.NewReader(bad)); err == nil {
+			t.Errorf("Parse(%q) não retornou erro", bad)
+		}
+	}
+}
+
+func TestGettext(t *testing.T) {
+	t.Cleanup(func() { SetLanguage("") })
+	if err := SetLanguage("en"); err != nil {
+		t.Fatal(err)
+	}
+	if got := Gettext("Uso:"); got != "Usage:" {
+		t.Errorf("en: %q", got)
+	}
+	if got := Gettext("mensagem que não está no catálogo"); got != "mensagem que não está no catálogo" {
+		t.Errorf("sem tradução: %q", got)
+	}
+	if err := SetLanguage("pt_BR"); err != nil {
+		t.Fatal(err)
+	}
+	if got := Gettext("Uso:"); got != "Uso:" {
+		t.Errorf("pt_BR: %q", got)
+	}
+	if err := SetLanguage("xx"); err == nil {
+		t.Error("SetLanguage(xx) não retornou erro")
+	}
+}
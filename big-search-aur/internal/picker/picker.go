@@ -0,0 +1,471 @@
+/*
+  picker.go - lista interativa com busca fuzzy e pré-visualização
+    Chili GNU/Linux - https://github.com/vcatafesta/chili/go
+    Chili GNU/Linux - https://chililinux.com
+    Chili GNU/Linux - https://chilios.com.br
+
+  Created: 2026/10/17
+  Altered: 2026/10/17
+
+  Copyright (c) 2024-2026, Vilmar Catafesta <vcatafesta@gmail.com>
+  All rights reserved.
+
+  Redistribution and use in source and binary forms, with or without
+  modification, are permitted provided that the following conditions
+  are met:
+  1. Redistributions of source code must retain the above copyright
+    notice, this list of conditions and the following disclaimer.
+  2. Redistributions in binary form must reproduce the above copyright
+    notice, this list of conditions and the following disclaimer in the
+    documentation and/or other materials provided with the distribution.
+
+  THIS SOFTWARE IS PROVIDED BY THE AUTHOR ``AS IS'' AND ANY EXPRESS OR
+  IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED WARRANTIES
+  OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE DISCLAIMED.
+  IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR ANY DIRECT, INDIRECT,
+  INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING, BUT
+  NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE,
+  DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY
+  THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT
+  (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF
+  THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+*/
+
+// Package picker implementa o seletor interativo do --pick: uma lista com
+// filtro fuzzy à esquerda e um painel de pré-visualização à direita.
+//
+// A interface é desenhada em /dev/tty, de modo que a saída padrão continua
+// livre para os nomes escolhidos (big-search-aur -Ss foo --pick | paru -S -).
+//
+// Teclas: digite para filtrar, ↑/↓ (Ctrl-P/Ctrl-N) movem, PgUp/PgDn pulam
+// uma página, Tab marca/desmarca, Ctrl-A marca todos os filtrados, Enter
+// confirma e Esc/Ctrl-C cancela.
+package picker
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/vcatafesta/chili-big-go/big-search-aur/internal/i18n"
+)
+
+// As mensagens passam pelo catálogo do big-search-aur (internal/i18n)
+var gettext = i18n.Gettext
+
+// ErrCancelled é retornado quando o usuário sai com Esc ou Ctrl-C.
+var ErrCancelled = errors.New(gettext("seleção cancelada"))
+
+// Picker descreve a lista a ser mostrada.
+type Picker struct {
+	Items   []string             // rótulos da lista
+	Preview func(i int) []string // linhas da pré-visualização do item i; roda fora do laço de eventos
+	Prompt  string               // texto antes do filtro
+	Multi   bool                 // permite marcar vários itens com Tab
+}
+
+type key int
+
+const (
+	keyRune key = iota
+	keyEnter
+	keyCancel
+	keyUp
+	keyDown
+	keyPageUp
+	keyPageDown
+	keyBackspace
+	keyClear
+	keyToggle
+	keyToggleAll
+)
+
+type event struct {
+	key key
+	r   rune
+}
+
+type preview struct {
+	index int
+	lines []string
+}
+
+type state struct {
+	query    []rune
+	filtered []int // índices de Items que passam no filtro, do melhor para o pior
+	cursor   int   // posição em filtered
+	offset   int   // primeira linha visível da lista
+	selected map[int]bool
+	previews map[int][]string
+	pending  map[int]bool
+}
+
+// Run mostra a lista e retorna os índices escolhidos, em ordem crescente. Sem
+// nenhum item marcado, o item sob o cursor é o escolhido.
+func (p *Picker) Run() ([]int, error) {
+	if len(p.Items) == 0 {
+		return nil, nil
+	}
+	term, err := openTerminal()
+	if err != nil {
+		return nil, fmt.Errorf(gettext("não foi possível abrir o terminal: %w"), err)
+	}
+	defer term.restore()
+
+	fmt.Fprint(term.tty, "\x1b[?1049h\x1b[?25l")
+	defer fmt.Fprint(term.tty, "\x1b[?25h\x1b[?1049l")
+
+	events := make(chan event, 16)
+	go readEvents(term.tty, events)
+
+	resize := make(chan os.Signal, 1)
+	notifyResize(resize)
+	defer signal.Stop(resize)
+
+	previews := make(chan preview, 4)
+	st := &state{
+		selected: make(map[int]bool),
+		previews: make(map[int][]string),
+		pending:  make(map[int]bool),
+	}
+	p.filter(st)
+
+	for {
+		p.requestPreview(st, previews)
+		p.draw(term, st)
+
+		select {
+		case pv := <-previews:
+			st.previews[pv.index] = pv.lines
+			delete(st.pending, pv.index)
+		case <-resize:
+		case ev, ok := <-events:
+			if !ok {
+				return nil, ErrCancelled
+			}
+			_, rows := term.size()
+			page := max(rows-2, 1)
+			switch ev.key {
+			case keyCancel:
+				return nil, ErrCancelled
+			case keyEnter:
+				return p.result(st), nil
+			case keyUp:
+				st.move(-1)
+			case keyDown:
+				st.move(1)
+			case keyPageUp:
+				st.move(-page)
+			case keyPageDown:
+				st.move(page)
+			case keyToggle:
+				if p.Multi && len(st.filtered) > 0 {
+					index := st.filtered[st.cursor]
+					st.selected[index] = !st.selected[index]
+					st.move(1)
+				}
+			case keyToggleAll:
+				if p.Multi {
+					for _, index := range st.filtered {
+						st.selected[index] = true
+					}
+				}
+			case keyBackspace:
+				if len(st.query) > 0 {
+					st.query = st.query[:len(st.query)-1]
+					p.filter(st)
+				}
+			case keyClear:
+				st.query = nil
+				p.filter(st)
+			case keyRune:
+				st.query = append(st.query, ev.r)
+				p.filter(st)
+			}
+		}
+	}
+}
+
+func (p *Picker) result(st *state) []int {
+	var chosen []int
+	for index, ok := range st.selected {
+		if ok {
+			chosen = append(chosen, index)
+		}
+	}
+	if len(chosen) == 0 && len(st.filtered) > 0 {
+		chosen = append(chosen, st.filtered[st.cursor])
+	}
+	sort.Ints(chosen)
+	return chosen
+}
+
+// filter recalcula a lista com o filtro atual
+func (p *Picker) filter(st *state) {
+	query := string(st.query)
+	type scored struct {
+		index int
+		score int
+	}
+	var matches []scored
+	for i, item := range p.Items {
+		if score, ok := Match(item, query); ok {
+			matches = append(matches, scored{i, score})
+		}
+	}
+	if query != "" {
+		sort.SliceStable(matches, func(a, b int) bool { return matches[a].score > matches[b].score })
+	}
+	st.filtered = st.filtered[:0]
+	for _, m := range matches {
+		st.filtered = append(st.filtered, m.index)
+	}
+	st.cursor, st.offset = 0, 0
+}
+
+func (st *state) move(delta int) {
+	if len(st.filtered) == 0 {
+		return
+	}
+	st.cursor = min(max(st.cursor+delta, 0), len(st.filtered)-1)
+}
+
+// requestPreview busca em segundo plano a pré-visualização do item sob o
+// cursor, se ela ainda não estiver pronta
+func (p *Picker) requestPreview(st *state, previews chan<- preview) {
+	if p.Preview == nil || len(st.filtered) == 0 {
+		return
+	}
+	index := st.filtered[st.cursor]
+	if _, ok := st.previews[index]; ok || st.pending[index] {
+		return
+	}
+	st.pending[index] = true
+	go func() {
+		previews <- preview{index: index, lines: p.Preview(index)}
+	}()
+}
+
+func (p *Picker) draw(term *terminal, st *state) {
+	cols, rows := term.size()
+	listWidth := cols
+	previewWidth := 0
+	if p.Preview != nil && cols >= 60 {
+		listWidth = max(cols*2/5, 24)
+		previewWidth = cols - listWidth - 3
+	}
+	listRows := max(rows-2, 1)
+	if st.cursor < st.offset {
+		st.offset = st.cursor
+	}
+	if st.cursor >= st.offset+listRows {
+		st.offset = st.cursor - listRows + 1
+	}
+
+	var previewLines []string
+	if previewWidth > 0 && len(st.filtered) > 0 {
+		index := st.filtered[st.cursor]
+		if lines, ok := st.previews[index]; ok {
+			for _, line := range lines {
+				previewLines = append(previewLines, wrap(line, previewWidth)...)
+			}
+		} else {
+			previewLines = []string{gettext("carregando...")}
+		}
+	}
+
+	var sb strings.Builder
+	sb.WriteString("\x1b[H")
+	for row := 0; row < rows; row++ {
+		var left string
+		switch {
+		case row == 0:
+			left = fit(p.Prompt+string(st.query)+"▏", listWidth)
+		case row == 1:
+			info := fmt.Sprintf("  %d/%d", len(st.filtered), len(p.Items))
+			if n := countSelected(st.selected); n > 0 {
+				info += fmt.Sprintf(gettext(" (%d marcados)"), n)
+			}
+			left = "\x1b[2m" + fit(info, listWidth) + "\x1b[0m"
+		default:
+			pos := st.offset + row - 2
+			if pos < len(st.filtered) {
+				index := st.filtered[pos]
+				mark := "  "
+				if st.selected[index] {
+					mark = "● "
+				}
+				line := fit(mark+p.Items[index], listWidth)
+				if pos == st.cursor {
+					line = "\x1b[7m" + line + "\x1b[0m"
+				}
+				left = line
+			} else {
+				left = strings.Repeat(" ", listWidth)
+			}
+		}
+		sb.WriteString(left)
+		if previewWidth > 0 {
+			sb.WriteString(" \x1b[2m│\x1b[0m ")
+			if row < len(previewLines) {
+				sb.WriteString(fit(previewLines[row], previewWidth))
+			}
+		}
+		sb.WriteString("\x1b[K")
+		if row < rows-1 {
+			sb.WriteString("\r\n")
+		}
+	}
+	term.tty.WriteString(sb.String())
+}
+
+func countSelected(selected map[int]bool) int {
+	n := 0
+	for _, ok := range selected {
+		if ok {
+			n++
+		}
+	}
+	return n
+}
+
+// fit corta ou completa com espaços até width colunas
+func fit(s string, width int) string {
+	runes := []rune(s)
+	if len(runes) > width {
+		if width <= 1 {
+			return string(runes[:width])
+		}
+		return string(runes[:width-1]) + "…"
+	}
+	return s + strings.Repeat(" ", width-len(runes))
+}
+
+// wrap quebra a linha em pedaços de até width colunas, preferindo os espaços
+func wrap(line string, width int) []string {
+	runes := []rune(line)
+	if len(runes) <= width {
+		return []string{line}
+	}
+	var lines []string
+	for len(runes) > width {
+		cut := width
+		for i := width; i > width/2; i-- {
+			if runes[i] == ' ' {
+				cut = i
+				break
+			}
+		}
+		lines = append(lines, string(runes[:cut]))
+		runes = []rune(strings.TrimLeft(string(runes[cut:]), " "))
+	}
+	if len(runes) > 0 {
+		lines = append(lines, string(runes))
+	}
+	return lines
+}
+
+// Match faz a busca fuzzy: todas as letras de query devem aparecer em text,
+// na ordem. A pontuação favorece letras consecutivas e o início de palavras.
+func Match(text, query string) (int, bool) {
+	if query == "" {
+		return 0, true
+	}
+	t := []rune(strings.ToLower(text))
+	q := []rune(strings.ToLower(query))
+	score, qi, last := 0, 0, -1
+	for ti := 0; ti < len(t) && qi < len(q); ti++ {
+		if t[ti] != q[qi] {
+			continue
+		}
+		score++
+		if last == ti-1 {
+			score += 5
+		}
+		if ti == 0 || !unicode.IsLetter(t[ti-1]) && !unicode.IsDigit(t[ti-1]) {
+			score += 3
+		}
+		if last >= 0 {
+			score -= min(ti-last-1, 3)
+		}
+		last = ti
+		qi++
+	}
+	if qi < len(q) {
+		return 0, false
+	}
+	return score, true
+}
+
+// readEvents converte os bytes lidos do tty em teclas
+func readEvents(tty *os.File, events chan<- event) {
+	defer close(events)
+	buf := make([]byte, 256)
+	for {
+		n, err := tty.Read(buf)
+		if err != nil {
+			return
+		}
+		for _, ev := range parseKeys(buf[:n]) {
+			events <- ev
+		}
+	}
+}
+
+func parseKeys(data []byte) []event {
+	var evs []event
+	for len(data) > 0 {
+		switch {
+		case hasPrefix(data, "\x1b[A"), hasPrefix(data, "\x1bOA"):
+			evs, data = append(evs, event{key: keyUp}), data[3:]
+		case hasPrefix(data, "\x1b[B"), hasPrefix(data, "\x1bOB"):
+			evs, data = append(evs, event{key: keyDown}), data[3:]
+		case hasPrefix(data, "\x1b[5~"):
+			evs, data = append(evs, event{key: keyPageUp}), data[4:]
+		case hasPrefix(data, "\x1b[6~"):
+			evs, data = append(evs, event{key: keyPageDown}), data[4:]
+		case data[0] == 0x1b && len(data) > 1 && (data[1] == '[' || data[1] == 'O'):
+			// Sequência desconhecida (setas laterais, F1...): descarta
+			end := 2
+			for end < len(data) && (data[end] < 0x40 || data[end] > 0x7e) {
+				end++
+			}
+			data = data[min(end+1, len(data)):]
+		default:
+			r, size := utf8.DecodeRune(data)
+			data = data[size:]
+			switch r {
+			case 0x1b, 0x03, 0x07: // Esc, Ctrl-C, Ctrl-G
+				evs = append(evs, event{key: keyCancel})
+			case '\r', '\n':
+				evs = append(evs, event{key: keyEnter})
+			case '\t':
+				evs = append(evs, event{key: keyToggle})
+			case 0x01:
+				evs = append(evs, event{key: keyToggleAll})
+			case 0x7f, 0x08:
+				evs = append(evs, event{key: keyBackspace})
+			case 0x15: // Ctrl-U
+				evs = append(evs, event{key: keyClear})
+			case 0x10: // Ctrl-P
+				evs = append(evs, event{key: keyUp})
+			case 0x0e: // Ctrl-N
+				evs = append(evs, event{key: keyDown})
+			default:
+				if unicode.IsPrint(r) {
+					evs = append(evs, event{key: keyRune, r: r})
+				}
+			}
+		}
+	}
+	return evs
+}
+
+func hasPrefix(data []byte, prefix string) bool {
+	return strings.HasPrefix(string(data), prefix)
+}
@@ -0,0 +1,119 @@
+package picker
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestMatch(t *testing.T) {
+	cases := []struct {
+		text, query string
+		ok          bool
+	}{
+		{"yay", "", true},
+		{"yay-bin", "yb", true},
+		{"yay-bin", "YB", true},
+		{"brave-bin", "bb", true},
+		{"brave-bin", "bv", true},
+		{"brave-bin", "vb", true},
+		{"brave-bin", "nb", false},
+		{"paru", "yay", false},
+		{"ação", "ão", true},
+		{"ab", "abc", false},
+	}
+	for _, c := range cases {
+		if _, ok := Match(c.text, c.query); ok != c.ok {
+			t.Errorf("Match(%q, %q) = %v, esperado %v", c.text, c.query, ok, c.ok)
+		}
+	}
+
+	// Cada par: o primeiro texto deve pontuar mais que o segundo
+	better := []struct {
+		query, best, worse string
+	}{
+		{"yay", "yay", "y-a-y"},              // letras consecutivas
+		{"bin", "brave-bin", "brabixn"},      // início de palavra
+		{"yb", "yay-bin", "yaybin"},          // b no início de palavra
+		{"ya", "yay", "xyay"},                // início do texto
+		{"ab", "a-b", "a----b"},              // distância menor entre letras
+		{"qt", "qt5-base", "quick-terminal"}, // consecutivas vencem dois inícios
+	}
+	for _, c := range better {
+		best, _ := Match(c.best, c.query)
+		worse, _ := Match(c.worse, c.query)
+		if best <= worse {
+			t.Errorf("%q: %q pontuou %d, %q pontuou %d", c.query, c.best, best, c.worse, worse)
+		}
+	}
+}
+
+func TestParseKeys(t *testing.T) {
+	cases := []struct {
+		in   string
+		want []event
+	}{
+		{"", nil},
+		{"ab", []event{{key: keyRune, r: 'a'}, {key: keyRune, r: 'b'}}},
+		{"ç", []event{{key: keyRune, r: 'ç'}}},
+		{"\x1b[A\x1bOB", []event{{key: keyUp}, {key: keyDown}}},
+		{"\x1b[5~\x1b[6~", []event{{key: keyPageUp}, {key: keyPageDown}}},
+		{"\x10\x0e", []event{{key: keyUp}, {key: keyDown}}},
+		{"\r\n", []event{{key: keyEnter}, {key: keyEnter}}},
+		{"\t\x01", []event{{key: keyToggle}, {key: keyToggleAll}}},
+		{"\x7f\x08\x15", []event{{key: keyBackspace}, {key: keyBackspace}, {key: keyClear}}},
+		{"\x1b", []event{{key: keyCancel}}},
+		{"\x03\x07", []event{{key: keyCancel}, {key: keyCancel}}},
+		// Setas laterais e F5 são descartadas sem engolir o que vem depois
+		{"\x1b[Cx\x1b[15~y", []event{{key: keyRune, r: 'x'}, {key: keyRune, r: 'y'}}},
+		// Sequência cortada no fim da leitura
+		{"\x1b[1;", nil},
+		// Outros caracteres de controle são ignorados
+		{"\x02z", []event{{key: keyRune, r: 'z'}}},
+	}
+	for _, c := range cases {
+		if got := parseKeys([]byte(c.in)); !reflect.DeepEqual(got, c.want) {
+			t.Errorf("parseKeys(%q) = %v, esperado %v", c.in, got, c.want)
+		}
+	}
+}
+
+func TestWrap(t *testing.T) {
+	cases := []struct {
+		line  string
+		width int
+		want  []string
+	}{
+		{"abc", 10, []string{"abc"}},
+		{"abc def ghi", 7, []string{"abc def", "ghi"}},
+		{"abc def ghi", 5, []string{"abc", "def", "ghi"}},
+		{"abcdefghij", 4, []string{"abcd", "efgh", "ij"}},
+		{"áéíóú", 2, []string{"áé", "íó", "ú"}},
+		{"", 3, []string{""}},
+	}
+	for _, c := range cases {
+		if got := wrap(c.line, c.width); !reflect.DeepEqual(got, c.want) {
+			t.Errorf("wrap(%q, %d) = %q, esperado %q", c.line, c.width, got, c.want)
+		}
+	}
+}
+
+func TestFit(t *testing.T) {
+	cases := []struct {
+		s     string
+		width int
+		want  string
+	}{
+		{"abc", 5, "abc  "},
+		{"abc", 3, "abc"},
+		{"abcdef", 4, "abc…"},
+		{"ação", 3, "aç…"},
+		{"abc", 1, "a"},
+		{"abc", 0, ""},
+		{"", 2, "  "},
+	}
+	for _, c := range cases {
+		if got := fit(c.s, c.width); got != c.want {
+			t.Errorf("fit(%q, %d) = %q, esperado %q", c.s, c.width, got, c.want)
+		}
+	}
+}
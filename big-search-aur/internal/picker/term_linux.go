@@ -0,0 +1,68 @@
+package picker
+
+import (
+	"os"
+	"os/signal"
+	"syscall"
+	"unsafe"
+)
+
+// terminal guarda o estado original do tty para restaurá-lo na saída
+type terminal struct {
+	tty   *os.File
+	state syscall.Termios
+}
+
+func openTerminal() (*terminal, error) {
+	tty, err := os.OpenFile("/dev/tty", os.O_RDWR, 0)
+	if err != nil {
+		return nil, err
+	}
+	t := &terminal{tty: tty}
+	if err := ioctl(tty.Fd(), syscall.TCGETS, unsafe.Pointer(&t.state)); err != nil {
+		tty.Close()
+		return nil, err
+	}
+
+	// Modo raw: sem eco, sem modo canônico e sem sinais (Ctrl-C vira tecla)
+	raw := t.state
+	raw.Iflag &^= syscall.IGNBRK | syscall.BRKINT | syscall.PARMRK | syscall.ISTRIP | syscall.INLCR | syscall.IGNCR | syscall.ICRNL | syscall.IXON
+	raw.Lflag &^= syscall.ECHO | syscall.ECHONL | syscall.ICANON | syscall.ISIG | syscall.IEXTEN
+	raw.Cflag &^= syscall.CSIZE | syscall.PARENB
+	raw.Cflag |= syscall.CS8
+	raw.Cc[syscall.VMIN] = 1
+	raw.Cc[syscall.VTIME] = 0
+	if err := ioctl(tty.Fd(), syscall.TCSETS, unsafe.Pointer(&raw)); err != nil {
+		tty.Close()
+		return nil, err
+	}
+	return t, nil
+}
+
+// notifyResize envia em c um sinal a cada mudança de tamanho do terminal
+func notifyResize(c chan<- os.Signal) {
+	signal.Notify(c, syscall.SIGWINCH)
+}
+
+func (t *terminal) restore() {
+	ioctl(t.tty.Fd(), syscall.TCSETS, unsafe.Pointer(&t.state))
+	t.tty.Close()
+}
+
+// size retorna colunas e linhas do terminal
+func (t *terminal) size() (int, int) {
+	var ws struct {
+		Row, Col, Xpixel, Ypixel uint16
+	}
+	if err := ioctl(t.tty.Fd(), syscall.TIOCGWINSZ, unsafe.Pointer(&ws)); err != nil || ws.Col == 0 || ws.Row == 0 {
+		return 80, 24
+	}
+	return int(ws.Col), int(ws.Row)
+}
+
+func ioctl(fd uintptr, request uintptr, arg unsafe.Pointer) error {
+	if _, _, errno := syscall.Syscall(syscall.SYS_IOCTL, fd, request, uintptr(arg)); errno != 0 {
+		return errno
+	}
+	return nil
+}
@@ -0,0 +1,25 @@
+//go:build !linux
+
+package picker
+
+import (
+	"errors"
+	"os"
+)
+
+// terminal só existe de fato no Linux, onde o modo raw é ligado com ioctl
+type terminal struct {
+	tty *os.File
+}
+
+func openTerminal() (*terminal, error) {
+	return nil, errors.New(gettext("seletor interativo disponível apenas no Linux"))
+}
+
+func notifyResize(c chan<- os.Signal) {}
+
+func (t *terminal) restore() {}
+
+func (t *terminal) size() (int, int) {
+	return 80, 24
+}
@@ -0,0 +1,294 @@
+/*
+  snapshot.go - extração segura dos snapshots (.tar.gz) do AUR
+    Chili GNU/Linux - https://github.com/vcatafesta/chili/go
+    Chili GNU/Linux - https://chililinux.com
+    Chili GNU/Linux - https://chilios.com.br
+
+  Created: 2026/10/17
+  Altered: 2026/10/17
+
+  Copyright (c) 2024-2026, Vilmar Catafesta <vcatafesta@gmail.com>
+  All rights reserved.
+
+  Redistribution and use in source and binary forms, with or without
+  modification, are permitted provided that the following conditions
+  are met:
+  1. Redistributions of source code must retain the above copyright
+    notice, this list of conditions and the following disclaimer.
+  2. Redistributions in binary form must reproduce the above copyright
+    notice, this list of conditions and the following disclaimer in the
+    documentation and/or other materials provided with the distribution.
+
+  THIS SOFTWARE IS PROVIDED BY THE AUTHOR ``AS IS'' AND ANY EXPRESS OR
+  IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED WARRANTIES
+  OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE DISCLAIMED.
+  IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR ANY DIRECT, INDIRECT,
+  INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING, BUT
+  NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE,
+  DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY
+  THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT
+  (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF
+  THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+*/
+
+// Package snapshot extrai os snapshots do AUR (/cgit/aur.git/snapshot/<base>.tar.gz)
+// sem confiar no conteúdo do arquivo: todas as entradas precisam estar
+// dentro de <base>/, caminhos absolutos ou com ".." são recusados e links
+// simbólicos só podem apontar para dentro do destino, sem passar por outro
+// link. O arquivo inteiro é validado antes de qualquer escrita em disco.
+package snapshot
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/vcatafesta/chili-big-go/big-search-aur/internal/i18n"
+)
+
+// As mensagens passam pelo catálogo do big-search-aur (internal/i18n)
+var gettext = i18n.Gettext
+
+// ErrNotGzip indica que a resposta não é um arquivo gzip (por exemplo, uma
+// página de erro em HTML)
+var ErrNotGzip = errors.New(gettext("o snapshot não é um arquivo gzip"))
+
+// IsGzip verifica a assinatura do gzip no início dos dados
+func IsGzip(data []byte) bool {
+	return len(data) >= 2 && data[0] == 0x1f && data[1] == 0x8b
+}
+
+// entry é uma entrada já validada, com o caminho relativo ao destino
+type entry struct {
+	header *tar.Header
+	rel    string
+	data   []byte
+}
+
+// Extract valida o snapshot e extrai o conteúdo de <base>/ em dest. Arquivos
+// existentes em dest são substituídos; os que não estão no snapshot (src/,
+// pkg/, fontes baixadas pelo makepkg) são mantidos.
+func Extract(data []byte, dest, base string) error {
+	if !IsGzip(data) {
+		return ErrNotGzip
+	}
+	entries, err := readEntries(data, base)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(dest, 0o755); err != nil {
+		return err
+	}
+	for _, e := range entries {
+		if err := write(dest, e); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// readEntries lê e valida todas as entradas antes de qualquer escrita
+func readEntries(data []byte, base string) ([]entry, error) {
+	gz, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrNotGzip, err)
+	}
+	defer gz.Close()
+
+	var entries []entry
+	symlinks := make(map[string]bool)
+	tr := tar.NewReader(gz)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf(gettext("snapshot corrompido: %w"), err)
+		}
+		if header.Typeflag == tar.TypeXGlobalHeader {
+			continue // pax_global_header do git archive
+		}
+
+		rel, err := relPath(header.Name, base)
+		if err != nil {
+			return nil, err
+		}
+		e := entry{header: header, rel: rel}
+		switch header.Typeflag {
+		case tar.TypeDir:
+		case tar.TypeReg:
+			if e.data, err = io.ReadAll(tr); err != nil {
+				return nil, fmt.Errorf(gettext("snapshot corrompido: %w"), err)
+			}
+		case tar.TypeSymlink:
+			if rel == "." {
+				return nil, fmt.Errorf(gettext("%s: o diretório base não pode ser um link"), header.Name)
+			}
+			symlinks[rel] = true
+		default:
+			return nil, fmt.Errorf(gettext("%s: tipo de entrada não suportado (%c)"), header.Name, header.Typeflag)
+		}
+		entries = append(entries, e)
+	}
+	if len(entries) == 0 {
+		return nil, errors.New(gettext("snapshot vazio"))
+	}
+
+	// Com todos os links conhecidos, nenhuma entrada pode passar por um link
+	// e nenhum link pode apontar para fora do destino
+	for _, e := range entries {
+		if through := throughSymlink(e.rel, symlinks); through != "" {
+			return nil, fmt.Errorf(gettext("%s: caminho passa pelo link %s"), e.header.Name, through)
+		}
+		if e.header.Typeflag != tar.TypeSymlink {
+			continue
+		}
+		link := e.header.Linkname
+		if link == "" || path.IsAbs(link) {
+			return nil, fmt.Errorf(gettext("%s: link para caminho absoluto %q"), e.header.Name, link)
+		}
+		if err := checkLink(e.rel, link, symlinks); err != nil {
+			return nil, fmt.Errorf(gettext("%s: link %q %v"), e.header.Name, link, err)
+		}
+	}
+	return entries, nil
+}
+
+// relPath converte "<base>/a/b" em "a/b", recusando o que estiver fora de base
+func relPath(name, base string) (string, error) {
+	if path.IsAbs(name) {
+		return "", fmt.Errorf(gettext("%s: caminho absoluto no snapshot"), name)
+	}
+	for _, part := range strings.Split(name, "/") {
+		if part == ".." {
+			return "", fmt.Errorf(gettext("%s: caminho com '..' no snapshot"), name)
+		}
+	}
+	clean := path.Clean(name)
+	if clean == base {
+		return ".", nil
+	}
+	if !strings.HasPrefix(clean, base+"/") {
+		return "", fmt.Errorf(gettext("%s: entrada fora do diretório %s/"), name, base)
+	}
+	return strings.TrimPrefix(clean, base+"/"), nil
+}
+
+// checkLink segue o alvo do link componente por componente, a partir do
+// diretório do link, como o kernel faria: o alvo não pode subir acima do
+// destino nem atravessar outro link (um "d/.." onde d é link para "." sai do
+// destino, embora path.Clean diga o contrário)
+func checkLink(rel, link string, symlinks map[string]bool) error {
+	var stack []string
+	if dir := path.Dir(rel); dir != "." {
+		stack = strings.Split(dir, "/")
+	}
+	parts := strings.Split(link, "/")
+	for i, part := range parts {
+		switch part {
+		case "", ".":
+			continue
+		case "..":
+			if len(stack) == 0 {
+				return errors.New(gettext("aponta para fora do destino"))
+			}
+			stack = stack[:len(stack)-1]
+		default:
+			stack = append(stack, part)
+			if current := strings.Join(stack, "/"); symlinks[current] && i < len(parts)-1 {
+				return fmt.Errorf(gettext("passa pelo link %s"), current)
+			}
+		}
+	}
+	return nil
+}
+
+// throughSymlink retorna o primeiro diretório de rel que é um link do snapshot
+func throughSymlink(rel string, symlinks map[string]bool) string {
+	parts := strings.Split(rel, "/")
+	for i := 1; i < len(parts); i++ {
+		if prefix := strings.Join(parts[:i], "/"); symlinks[prefix] {
+			return prefix
+		}
+	}
+	return ""
+}
+
+// write grava uma entrada validada. Links já existentes no caminho não são
+// seguidos: a entrada antiga é removida antes de criar a nova.
+func write(dest string, e entry) error {
+	target := filepath.Join(dest, filepath.FromSlash(e.rel))
+	if err := checkParents(dest, e.rel); err != nil {
+		return err
+	}
+	mode := os.FileMode(e.header.Mode).Perm() // sem setuid/setgid/sticky
+
+	switch e.header.Typeflag {
+	case tar.TypeDir:
+		if info, err := os.Lstat(target); err == nil && !info.IsDir() {
+			if err := os.Remove(target); err != nil {
+				return err
+			}
+		}
+		return os.MkdirAll(target, 0o755)
+	case tar.TypeReg:
+		if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+			return err
+		}
+		if err := removeFile(target); err != nil {
+			return err
+		}
+		f, err := os.OpenFile(target, os.O_WRONLY|os.O_CREATE|os.O_EXCL, mode|0o600)
+		if err != nil {
+			return err
+		}
+		if _, err := f.Write(e.data); err != nil {
+			f.Close()
+			return err
+		}
+		return f.Close()
+	default: // tar.TypeSymlink
+		if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+			return err
+		}
+		if err := removeFile(target); err != nil {
+			return err
+		}
+		return os.Symlink(e.header.Linkname, target)
+	}
+}
+
+// checkParents recusa escrever através de links que já estejam no destino
+func checkParents(dest, rel string) error {
+	parts := strings.Split(rel, "/")
+	for i := 1; i < len(parts); i++ {
+		dir := filepath.Join(dest, filepath.FromSlash(strings.Join(parts[:i], "/")))
+		if info, err := os.Lstat(dir); err == nil && info.Mode()&os.ModeSymlink != 0 {
+			return fmt.Errorf(gettext("%s: o diretório %s é um link"), rel, dir)
+		}
+	}
+	return nil
+}
+
+// removeFile apaga o arquivo ou link existente; diretórios não são apagados
+func removeFile(target string) error {
+	info, err := os.Lstat(target)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if info.IsDir() {
+		return fmt.Errorf(gettext("%s: já existe um diretório com esse nome"), target)
+	}
+	return os.Remove(target)
+}
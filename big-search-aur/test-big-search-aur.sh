@@ -88,5 +88,5 @@ if [[ -z $search ]] ; then
 	search=$backup
 fi
 #json=$(go run big-search-aur.go -Ss $search --by-name | jq -c '{Name, Version, Description}')
-json=$(go run big-search-aur.go -Ss $search --by-name | jq -c '.[] | {Name, Version, Description}')
+json=$(go run big-search-aur.go -Ss $search --by-name | jq -c '.results[] | {Name, Version, Description}')
 echo "$json"
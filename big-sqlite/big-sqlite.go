@@ -35,6 +35,7 @@
 package main
 
 import (
+	"colors"
 	"database/sql"
 	"encoding/json"
 	"fmt"
@@ -54,25 +55,9 @@ const (
   _COPYRIGHT_ = "Copyright (C) 2024 Vilmar Catafesta, <vcatafesta@gmail.com>"
 )
 
-// Constantes para cores ANSI
-const (
-  reset   = "\x1b[0m"
-  red     = "\x1b[31m"
-  green   = "\x1b[32m"
-  yellow  = "\x1b[33m"
-  blue    = "\x1b[34m"
-  magenta = "\x1b[35m"
-  cyan    = "\x1b[36m"
-  white   = "\x1b[37m"
-  Reset   = "\x1b[0m"
-  Red     = "\x1b[31m"
-  Green   = "\x1b[32m"
-  Yellow  = "\x1b[33m"
-  Blue    = "\x1b[34m"
-  Magenta = "\x1b[35m"
-  Cyan    = "\x1b[36m"
-  White   = "\x1b[37m"
-)
+// Cores ANSI da saída padrão (cout) e da saída de erro (cerr). Ficam vazias
+// quando a saída não é um terminal, com NO_COLOR ou com --color=never.
+var cout, cerr = &colors.Stdout, &colors.Stderr
 
 // Estrutura de dados para armazenar informações do pacote
 type PackageInfo struct {
@@ -159,7 +144,7 @@ func processPackages(rootDir string, db *sql.DB, quiet bool) {
 
 			if exists {
 				if !quiet {
-					p("Registro #", count, Red+"Pacote", Cyan+packageName, Red+"já existe no banco de dados.", Reset)
+					p("Registro #", count, cout.Red+"Pacote", cout.Cyan+packageName, cout.Red+"já existe no banco de dados.", cout.Reset)
 				}
 				continue
 			}
@@ -251,14 +236,14 @@ func searchAndPrintAllPackageJSON(db *sql.DB) {
 		foundPackage, err := searchPackage(db, pkg.Name)
 		if err != nil {
 			// Se ocorrer um erro, imprima em vermelho
-			log.Printf("big-sqlite %sErro:'%s' %s%v\n", Red, pkg.Name, Reset, err)
+			log.Printf("big-sqlite %sErro:'%s' %s%v\n", cerr.Red, pkg.Name, cerr.Reset, err)
 		} else {
 			// Converte o resultado em JSON e imprime em verde
 			jsonResult, err := json.Marshal(foundPackage)
 			if err != nil {
-				log.Printf("%sErro ao serializar para JSON:%s %v\n", Red, Reset, err)
+				log.Printf("%sErro ao serializar para JSON:%s %v\n", cerr.Red, cerr.Reset, err)
 			} else {
-				fmt.Printf("%s%s%s\n", Green, string(jsonResult), Reset)
+				fmt.Printf("%s%s%s\n", cout.Green, string(jsonResult), cout.Reset)
 				println()
 			}
 		}
@@ -270,16 +255,16 @@ func searchAndPrintPackageJSON(db *sql.DB, packageName string) {
 	foundPackage, err := searchPackage(db, packageName)
 	if err != nil {
 		// Se ocorrer um erro, imprima em vermelho
-		log.Printf("big-sqlite %sErro:'%s' %s%v\n", Red, packageName, Reset, err)
+		log.Printf("big-sqlite %sErro:'%s' %s%v\n", cerr.Red, packageName, cerr.Reset, err)
 		os.Exit(1)
 	} else {
 		// Converte o resultado em JSON e imprime em verde
 		jsonResult, err := json.Marshal(foundPackage)
 		if err != nil {
-			log.Printf("%sErro ao serializar para JSON:%s %v\n", Red, Reset, err)
+			log.Printf("%sErro ao serializar para JSON:%s %v\n", cerr.Red, cerr.Reset, err)
 			os.Exit(1)
 		} else {
-			fmt.Printf("%s%s%s\n", Green, string(jsonResult), Reset)
+			fmt.Printf("%s%s%s\n", cout.Green, string(jsonResult), cout.Reset)
 			os.Exit(0)
 		}
 	}
@@ -289,7 +274,7 @@ func searchAndPrintPackage(db *sql.DB, packageName string) {
 	pkg, err := searchPackage(db, packageName)
 	if err != nil {
 		// Se ocorrer um erro, imprima em vermelho
-		log.Printf("big-sqlite %sErro:'%s' %s%v\n", Red, pkg.Name, Reset, err)
+		log.Printf("big-sqlite %sErro:'%s' %s%v\n", cerr.Red, pkg.Name, cerr.Reset, err)
 		os.Exit(1)
 	} else {
 
@@ -349,11 +334,18 @@ func main() {
 		helpFlag    = pflag.BoolP("help", "h", false, "Mostra a mensagem de uso")
 		jsonFlag    = pflag.BoolP("json", "J", false, "Usa o formato de saída JSON")
 		listFlag    = pflag.BoolP("list", "L", false, "Lista todos pacotes do BD")
+		colorFlag   = pflag.String("color", "auto", "Cores na saída: auto (só em terminal e sem NO_COLOR), always ou never")
 	)
 
 	// Parse os argumentos usando pflag
 	pflag.Parse()
 
+	colorMode, err := colors.ParseMode(*colorFlag)
+	if err != nil {
+		log.Fatal(err)
+	}
+	colors.Setup(colorMode)
+
 	// Analise os argumentos da linha de comando
 	switch {
 	case *helpFlag || len(os.Args) == 1:
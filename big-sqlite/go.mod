@@ -3,6 +3,9 @@ module github.com/vcatafesta/chili-big-go/big-sqlite
 go 1.23.0
 
 require (
+	colors v0.0.0
 	github.com/mattn/go-sqlite3 v1.14.22
 	github.com/ogier/pflag v0.0.1
 )
+
+replace colors => ../colors
@@ -0,0 +1,92 @@
+/*
+  big-vercmp - compara versões de pacotes como o vercmp do pacman
+    Chili GNU/Linux - https://github.com/vcatafesta/chili/go
+    Chili GNU/Linux - https://chililinux.com
+    Chili GNU/Linux - https://chilios.com.br
+
+  Created: 2026/10/17
+  Altered: 2026/10/17
+
+  Copyright (c) 2024-2026, Vilmar Catafesta <vcatafesta@gmail.com>
+  All rights reserved.
+
+  Redistribution and use in source and binary forms, with or without
+  modification, are permitted provided that the following conditions
+  are met:
+  1. Redistributions of source code must retain the above copyright
+    notice, this list of conditions and the following disclaimer.
+  2. Redistributions in binary form must reproduce the above copyright
+    notice, this list of conditions and the following disclaimer in the
+    documentation and/or other materials provided with the distribution.
+
+  THIS SOFTWARE IS PROVIDED BY THE AUTHOR ``AS IS'' AND ANY EXPRESS OR
+  IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED WARRANTIES
+  OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE DISCLAIMED.
+  IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR ANY DIRECT, INDIRECT,
+  INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING, BUT
+  NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE,
+  DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY
+  THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT
+  (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF
+  THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+*/
+
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"vercmp"
+)
+
+const (
+	_APP_       = "big-vercmp"
+	_PKGDESC_   = "Compara versões de pacotes (epoch:pkgver-pkgrel)"
+	_VERSION_   = "0.1.0-20261017"
+	_COPYRIGHT_ = "Copyright (C) 2024-2026 Vilmar Catafesta, <vcatafesta@gmail.com>"
+)
+
+// Constantes para cores ANSI
+const (
+	reset = "\x1b[0m"
+	red   = "\x1b[31m"
+	green = "\x1b[32m"
+	blue  = "\x1b[34m"
+	cyan  = "\x1b[36m"
+)
+
+var p = fmt.Println
+
+func main() {
+	args := os.Args[1:]
+	if len(args) == 1 {
+		switch args[0] {
+		case "-h", "--help":
+			printUsage()
+			return
+		case "-V", "--version":
+			p(red + _APP_ + " - " + _PKGDESC_ + reset)
+			p(cyan + _APP_ + " - v" + _VERSION_ + reset)
+			p("   " + _COPYRIGHT_ + reset)
+			return
+		}
+	}
+	if len(args) != 2 {
+		printUsage()
+		os.Exit(1)
+	}
+	// Mesma saída do vercmp do pacman: -1, 0 ou 1 em stdout
+	p(vercmp.Compare(args[0], args[1]))
+}
+
+func printUsage() {
+	p("Uso:")
+	fmt.Printf("%s%-20s %s%s%s%s%s\n", blue, "  big-vercmp", green, "<versão1> <versão2>", cyan, " # compara duas versões", reset)
+	p("    Mostra:")
+	fmt.Printf("%s%-20s %s%s%s\n", blue, "  -1", reset, "se versão1 < versão2", reset)
+	fmt.Printf("%s%-20s %s%s%s\n", blue, "  0", reset, "se versão1 == versão2", reset)
+	fmt.Printf("%s%-20s %s%s%s\n", blue, "  1", reset, "se versão1 > versão2", reset)
+	p("    Exemplo:")
+	p(`      [[ $(big-vercmp "$local" "$aur") -lt 0 ]] && echo "atualização disponível"`)
+}
@@ -0,0 +1,7 @@
+module big-vercmp
+
+go 1.23.0
+
+require vercmp v0.0.0
+
+replace vercmp => ../vercmp
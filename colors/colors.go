@@ -31,8 +31,24 @@
   THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
 */
 
+// Package colors centraliza as cores ANSI dos utilitários big-*.
+//
+// As constantes são os códigos brutos. Para texto que pode acabar em um
+// arquivo de log ou em $(...) use as paletas Stdout e Stderr, que ficam
+// vazias quando a saída correspondente não é um terminal, quando NO_COLOR
+// está definida (https://no-color.org) ou com --color=never:
+//
+//	colors.Setup(mode) // depois de ler --color=auto|always|never
+//	fmt.Println(colors.Stdout.Cyan + "Uso:" + colors.Stdout.Reset)
+//	log.Println(colors.Stderr.Red + "Erro" + colors.Stderr.Reset)
 package colors
 
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
 // Constantes para cores ANSI
 const (
 	reset   = "\x1b[0m"
@@ -52,3 +68,127 @@ const (
 	Cyan    = "\x1b[36m"
 	White   = "\x1b[37m"
 )
+
+// Palette são as cores de uma saída; com as cores desligadas todos os campos
+// ficam vazios e podem ser concatenados sem efeito.
+type Palette struct {
+	Reset   string
+	Black   string
+	Red     string
+	Green   string
+	Yellow  string
+	Blue    string
+	Magenta string
+	Cyan    string
+	White   string
+	Gray    string
+}
+
+// ANSI é a paleta com as cores ligadas.
+var ANSI = Palette{
+	Reset:   Reset,
+	Black:   "\x1b[30m",
+	Red:     Red,
+	Green:   Green,
+	Yellow:  Yellow,
+	Blue:    Blue,
+	Magenta: Magenta,
+	Cyan:    Cyan,
+	White:   White,
+	Gray:    "\x1b[90m",
+}
+
+// Paletas da saída padrão e da saída de erro, decididas separadamente:
+// big-search-aur -Ss foo > lista.txt continua com as mensagens de erro
+// coloridas no terminal.
+var (
+	Stdout Palette
+	Stderr Palette
+)
+
+// Mode é o valor do --color.
+type Mode int
+
+const (
+	Auto   Mode = iota // cores só em terminal e sem NO_COLOR (padrão)
+	Always             // sempre, mesmo com NO_COLOR ou em pipes
+	Never              // nunca
+)
+
+// Modes são os nomes aceitos pelo --color, na ordem de Mode.
+var Modes = []string{"auto", "always", "never"}
+
+func (m Mode) String() string {
+	return Modes[m]
+}
+
+// ParseMode converte o nome do modo (auto, always ou never).
+func ParseMode(value string) (Mode, error) {
+	for i, name := range Modes {
+		if value == name {
+			return Mode(i), nil
+		}
+	}
+	return Auto, fmt.Errorf("--color requer %s", strings.Join(Modes, ", "))
+}
+
+func init() {
+	Setup(Auto)
+}
+
+// Setup recalcula Stdout e Stderr para o modo escolhido.
+func Setup(mode Mode) {
+	Stdout = New(Enabled(mode, os.Stdout))
+	Stderr = New(Enabled(mode, os.Stderr))
+}
+
+// New retorna a paleta ANSI ou, com enabled falso, a paleta vazia.
+func New(enabled bool) Palette {
+	if enabled {
+		return ANSI
+	}
+	return Palette{}
+}
+
+// Enabled diz se a saída f deve ter cores no modo dado. Em Auto, NO_COLOR
+// com qualquer valor não vazio desliga as cores; sem ela, só há cores se f
+// for um terminal.
+func Enabled(mode Mode, f *os.File) bool {
+	switch mode {
+	case Always:
+		return true
+	case Never:
+		return false
+	}
+	if os.Getenv("NO_COLOR") != "" {
+		return false
+	}
+	return IsTerminal(f)
+}
+
+// FromArgs procura --color=<modo> e --color <modo> em args e retorna o último
+// modo encontrado (Auto se não houver) e args sem essas opções.
+func FromArgs(args []string) (Mode, []string, error) {
+	mode := Auto
+	rest := make([]string, 0, len(args))
+	for i := 0; i < len(args); i++ {
+		value, found := strings.CutPrefix(args[i], "--color=")
+		if !found {
+			if args[i] != "--color" {
+				rest = append(rest, args[i])
+				continue
+			}
+			if i+1 >= len(args) {
+				return Auto, args, fmt.Errorf("--color requer %s", strings.Join(Modes, ", "))
+			}
+			i++
+			value = args[i]
+		}
+		m, err := ParseMode(value)
+		if err != nil {
+			return Auto, args, err
+		}
+		mode = m
+	}
+	return mode, rest, nil
+}
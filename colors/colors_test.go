@@ -0,0 +1,62 @@
+package colors
+
+import (
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestFromArgs(t *testing.T) {
+	cases := []struct {
+		args []string
+		mode Mode
+		rest string
+	}{
+		{[]string{"-Ss", "yay"}, Auto, "-Ss yay"},
+		{[]string{"--color=never", "-Ss", "yay"}, Never, "-Ss yay"},
+		{[]string{"-Ss", "--color", "always", "yay"}, Always, "-Ss yay"},
+		{[]string{"--color=never", "--color=auto"}, Auto, ""},
+	}
+	for _, c := range cases {
+		mode, rest, err := FromArgs(c.args)
+		if err != nil || mode != c.mode || strings.Join(rest, " ") != c.rest {
+			t.Errorf("FromArgs(%q) = %v, %q, %v", c.args, mode, rest, err)
+		}
+	}
+	for _, bad := range [][]string{{"--color=sempre"}, {"-Ss", "--color"}} {
+		if _, _, err := FromArgs(bad); err == nil {
+			t.Errorf("FromArgs(%q) não retornou erro", bad)
+		}
+	}
+}
+
+func TestEnabled(t *testing.T) {
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	defer w.Close()
+
+	t.Setenv("NO_COLOR", "")
+	if Enabled(Auto, w) {
+		t.Error("auto com pipe ligou as cores")
+	}
+	if !Enabled(Always, w) {
+		t.Error("always com pipe desligou as cores")
+	}
+	if IsTerminal(w) {
+		t.Error("pipe reconhecido como terminal")
+	}
+
+	t.Setenv("NO_COLOR", "1")
+	if !Enabled(Always, w) {
+		t.Error("always não prevalece sobre NO_COLOR")
+	}
+	if Enabled(Auto, os.Stdout) {
+		t.Error("NO_COLOR não desligou as cores")
+	}
+	if New(false) != (Palette{}) || New(true).Red != Red {
+		t.Error("New não retornou as paletas esperadas")
+	}
+}
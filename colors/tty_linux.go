@@ -0,0 +1,15 @@
+package colors
+
+import (
+	"os"
+	"syscall"
+	"unsafe"
+)
+
+// IsTerminal diz se f é um terminal (isatty): o ioctl TCGETS só funciona em
+// um tty, ao contrário de os.ModeCharDevice, que também vale para /dev/null.
+func IsTerminal(f *os.File) bool {
+	var termios syscall.Termios
+	_, _, errno := syscall.Syscall(syscall.SYS_IOCTL, f.Fd(), syscall.TCGETS, uintptr(unsafe.Pointer(&termios)))
+	return errno == 0
+}
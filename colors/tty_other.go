@@ -0,0 +1,12 @@
+//go:build !linux
+
+package colors
+
+import "os"
+
+// IsTerminal diz se f é um dispositivo de caractere, a aproximação possível
+// de isatty sem o ioctl do Linux.
+func IsTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
@@ -0,0 +1,3 @@
+module srcinfo
+
+go 1.23.0
@@ -0,0 +1,454 @@
+/*
+  srcinfo.go - leitura do .SRCINFO dos pacotes do AUR
+    Chili GNU/Linux - https://github.com/vcatafesta/chili/go
+    Chili GNU/Linux - https://chililinux.com
+    Chili GNU/Linux - https://chilios.com.br
+
+  Created: 2026/10/17
+  Altered: 2026/10/17
+
+  Copyright (c) 2024-2026, Vilmar Catafesta <vcatafesta@gmail.com>
+  All rights reserved.
+
+  Redistribution and use in source and binary forms, with or without
+  modification, are permitted provided that the following conditions
+  are met:
+  1. Redistributions of source code must retain the above copyright
+    notice, this list of conditions and the following disclaimer.
+  2. Redistributions in binary form must reproduce the above copyright
+    notice, this list of conditions and the following disclaimer in the
+    documentation and/or other materials provided with the distribution.
+
+  THIS SOFTWARE IS PROVIDED BY THE AUTHOR ``AS IS'' AND ANY EXPRESS OR
+  IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED WARRANTIES
+  OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE DISCLAIMED.
+  IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR ANY DIRECT, INDIRECT,
+  INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING, BUT
+  NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE,
+  DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY
+  THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT
+  (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF
+  THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+*/
+
+// Package srcinfo lê o .SRCINFO gerado pelo makepkg --printsrcinfo, sem
+// executar o PKGBUILD. Entende split packages (uma seção pkgbase seguida de
+// seções pkgname que sobrescrevem os valores da base), chaves específicas de
+// arquitetura (depends_x86_64, source_aarch64, sha256sums_x86_64, ...) e
+// relaciona cada source com os seus checksums.
+//
+// Outros módulos do repositório podem importá-lo com:
+//
+//	require srcinfo v0.0.0
+//	replace srcinfo => ../srcinfo
+package srcinfo
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"strings"
+)
+
+// ChecksumKinds são as chaves de checksum aceitas pelo makepkg
+var ChecksumKinds = []string{"cksums", "md5sums", "sha1sums", "sha224sums", "sha256sums", "sha384sums", "sha512sums", "b2sums"}
+
+// Chaves que podem ter sufixo de arquitetura (depends_x86_64)
+var archKeys = append([]string{"source", "depends", "makedepends", "checkdepends", "optdepends", "provides", "conflicts", "replaces"}, ChecksumKinds...)
+
+// Value é um valor de uma chave com sufixo de arquitetura opcional
+type Value struct {
+	Arch  string `json:"arch,omitempty"` // vazio = todas as arquiteturas
+	Value string `json:"value"`
+}
+
+// Package são os campos que uma seção pkgname pode sobrescrever
+type Package struct {
+	Pkgname    string   `json:"pkgname"`
+	Pkgdesc    string   `json:"pkgdesc,omitempty"`
+	URL        string   `json:"url,omitempty"`
+	Arch       []string `json:"arch,omitempty"`
+	License    []string `json:"license,omitempty"`
+	Groups     []string `json:"groups,omitempty"`
+	Depends    []Value  `json:"depends,omitempty"`
+	OptDepends []Value  `json:"optdepends,omitempty"`
+	Provides   []Value  `json:"provides,omitempty"`
+	Conflicts  []Value  `json:"conflicts,omitempty"`
+	Replaces   []Value  `json:"replaces,omitempty"`
+	Backup     []string `json:"backup,omitempty"`
+	Options    []string `json:"options,omitempty"`
+	Install    string   `json:"install,omitempty"`
+	Changelog  string   `json:"changelog,omitempty"`
+
+	keys map[string]bool // chaves presentes na seção (com sufixo de arquitetura)
+}
+
+// Srcinfo é o .SRCINFO completo. Base guarda os valores da seção pkgbase; os
+// pacotes guardam apenas o que cada seção pkgname declarou. Use Package para
+// obter os valores efetivos de um pacote.
+type Srcinfo struct {
+	Pkgbase      string             `json:"pkgbase"`
+	Pkgver       string             `json:"pkgver"`
+	Pkgrel       string             `json:"pkgrel"`
+	Epoch        string             `json:"epoch,omitempty"`
+	Base         Package            `json:"-"`
+	MakeDepends  []Value            `json:"makedepends,omitempty"`
+	CheckDepends []Value            `json:"checkdepends,omitempty"`
+	Source       []Value            `json:"source,omitempty"`
+	NoExtract    []string           `json:"noextract,omitempty"`
+	ValidPGPKeys []string           `json:"validpgpkeys,omitempty"`
+	Checksums    map[string][]Value `json:"checksums,omitempty"` // chave: sha256sums, b2sums, ...
+	Packages     []Package          `json:"-"`
+}
+
+// Source é uma entrada do source com os checksums da mesma posição
+type Source struct {
+	Arch      string            `json:"arch,omitempty"`
+	Source    string            `json:"source"`
+	Filename  string            `json:"filename"`
+	URL       string            `json:"url,omitempty"` // vazio para arquivos que acompanham o PKGBUILD
+	Checksums map[string]string `json:"checksums"`
+	Skip      bool              `json:"skip"` // algum checksum é SKIP
+}
+
+// ParseFile lê o .SRCINFO do arquivo
+func ParseFile(filePath string) (*Srcinfo, error) {
+	f, err := os.Open(filePath)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	info, err := Parse(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", filePath, err)
+	}
+	return info, nil
+}
+
+// ParseBytes lê o .SRCINFO já carregado em memória
+func ParseBytes(data []byte) (*Srcinfo, error) {
+	return Parse(bytes.NewReader(data))
+}
+
+// Parse lê o .SRCINFO: linhas "chave = valor", com as seções iniciadas por
+// pkgbase e pkgname. Comentários (#) e linhas em branco são ignorados.
+func Parse(r io.Reader) (*Srcinfo, error) {
+	info := &Srcinfo{Checksums: make(map[string][]Value)}
+	var current *Package // nil enquanto estiver na seção pkgbase
+	inBase := false
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		key, value, ok := strings.Cut(line, "=")
+		if !ok {
+			return nil, fmt.Errorf("linha %d: esperava 'chave = valor': %q", lineNumber, line)
+		}
+		key, value = strings.TrimSpace(key), strings.TrimSpace(value)
+
+		switch key {
+		case "pkgbase":
+			if inBase || len(info.Packages) > 0 {
+				return nil, fmt.Errorf("linha %d: pkgbase repetido", lineNumber)
+			}
+			info.Pkgbase = value
+			inBase = true
+			continue
+		case "pkgname":
+			if !inBase {
+				return nil, fmt.Errorf("linha %d: pkgname antes do pkgbase", lineNumber)
+			}
+			info.Packages = append(info.Packages, Package{Pkgname: value, keys: make(map[string]bool)})
+			current = &info.Packages[len(info.Packages)-1]
+			continue
+		}
+		if !inBase {
+			return nil, fmt.Errorf("linha %d: %s antes do pkgbase", lineNumber, key)
+		}
+
+		if current != nil {
+			if err := current.set(key, value); err != nil {
+				return nil, fmt.Errorf("linha %d: %w", lineNumber, err)
+			}
+			continue
+		}
+		if err := info.set(key, value); err != nil {
+			return nil, fmt.Errorf("linha %d: %w", lineNumber, err)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if info.Pkgbase == "" {
+		return nil, fmt.Errorf("pkgbase não encontrado")
+	}
+	if len(info.Packages) == 0 {
+		return nil, fmt.Errorf("nenhum pkgname em %s", info.Pkgbase)
+	}
+	return info, nil
+}
+
+// splitKey separa "depends_x86_64" em ("depends", "x86_64")
+func splitKey(key string) (string, string) {
+	for _, name := range archKeys {
+		if arch, ok := strings.CutPrefix(key, name+"_"); ok && arch != "" {
+			return name, arch
+		}
+	}
+	return key, ""
+}
+
+// set grava uma chave da seção pkgbase
+func (s *Srcinfo) set(key, value string) error {
+	name, arch := splitKey(key)
+	switch name {
+	case "pkgver":
+		s.Pkgver = value
+	case "pkgrel":
+		s.Pkgrel = value
+	case "epoch":
+		s.Epoch = value
+	case "makedepends":
+		s.MakeDepends = appendValue(s.MakeDepends, arch, value)
+	case "checkdepends":
+		s.CheckDepends = appendValue(s.CheckDepends, arch, value)
+	case "source":
+		s.Source = appendValue(s.Source, arch, value)
+	case "noextract":
+		s.NoExtract = appendString(s.NoExtract, value)
+	case "validpgpkeys":
+		s.ValidPGPKeys = appendString(s.ValidPGPKeys, value)
+	default:
+		for _, kind := range ChecksumKinds {
+			if name == kind {
+				s.Checksums[kind] = appendValue(s.Checksums[kind], arch, value)
+				return nil
+			}
+		}
+		if s.Base.keys == nil {
+			s.Base.keys = make(map[string]bool)
+		}
+		return s.Base.set(key, value)
+	}
+	return nil
+}
+
+// set grava uma chave de pacote. Chaves desconhecidas são ignoradas, para
+// aceitar campos novos do makepkg.
+func (p *Package) set(key, value string) error {
+	name, arch := splitKey(key)
+	if p.Pkgname != "" && baseOnly(name) {
+		return fmt.Errorf("%s só pode aparecer na seção pkgbase", key)
+	}
+	p.keys[key] = true
+	switch name {
+	case "pkgdesc":
+		p.Pkgdesc = value
+	case "url":
+		p.URL = value
+	case "arch":
+		p.Arch = appendString(p.Arch, value)
+	case "license":
+		p.License = appendString(p.License, value)
+	case "groups":
+		p.Groups = appendString(p.Groups, value)
+	case "depends":
+		p.Depends = appendValue(p.Depends, arch, value)
+	case "optdepends":
+		p.OptDepends = appendValue(p.OptDepends, arch, value)
+	case "provides":
+		p.Provides = appendValue(p.Provides, arch, value)
+	case "conflicts":
+		p.Conflicts = appendValue(p.Conflicts, arch, value)
+	case "replaces":
+		p.Replaces = appendValue(p.Replaces, arch, value)
+	case "backup":
+		p.Backup = appendString(p.Backup, value)
+	case "options":
+		p.Options = appendString(p.Options, value)
+	case "install":
+		p.Install = value
+	case "changelog":
+		p.Changelog = value
+	}
+	return nil
+}
+
+// baseOnly informa se a chave só é válida na seção pkgbase
+func baseOnly(name string) bool {
+	switch name {
+	case "pkgver", "pkgrel", "epoch", "makedepends", "checkdepends", "source", "noextract", "validpgpkeys":
+		return true
+	}
+	for _, kind := range ChecksumKinds {
+		if name == kind {
+			return true
+		}
+	}
+	return false
+}
+
+// Um valor vazio ("depends = ") registra a chave sem acrescentar nada: é
+// assim que uma seção pkgname zera um valor herdado da base
+func appendValue(list []Value, arch, value string) []Value {
+	if value == "" {
+		return list
+	}
+	return append(list, Value{Arch: arch, Value: value})
+}
+
+func appendString(list []string, value string) []string {
+	if value == "" {
+		return list
+	}
+	return append(list, value)
+}
+
+// Version retorna a versão completa, epoch:pkgver-pkgrel (sem epoch se for 0)
+func (s *Srcinfo) Version() string {
+	version := s.Pkgver + "-" + s.Pkgrel
+	if s.Epoch != "" && s.Epoch != "0" {
+		version = s.Epoch + ":" + version
+	}
+	return version
+}
+
+// Package retorna os valores efetivos do pacote: o que a seção pkgname
+// declarou e, para o resto, os valores da pkgbase
+func (s *Srcinfo) Package(pkgname string) (Package, bool) {
+	for _, pkg := range s.Packages {
+		if pkg.Pkgname != pkgname {
+			continue
+		}
+		base := s.Base
+		merged := Package{
+			Pkgname:    pkg.Pkgname,
+			Pkgdesc:    pick(pkg.keys["pkgdesc"], pkg.Pkgdesc, base.Pkgdesc),
+			URL:        pick(pkg.keys["url"], pkg.URL, base.URL),
+			Arch:       pick(pkg.keys["arch"], pkg.Arch, base.Arch),
+			License:    pick(pkg.keys["license"], pkg.License, base.License),
+			Groups:     pick(pkg.keys["groups"], pkg.Groups, base.Groups),
+			Depends:    mergeValues("depends", pkg.keys, pkg.Depends, base.Depends),
+			OptDepends: mergeValues("optdepends", pkg.keys, pkg.OptDepends, base.OptDepends),
+			Provides:   mergeValues("provides", pkg.keys, pkg.Provides, base.Provides),
+			Conflicts:  mergeValues("conflicts", pkg.keys, pkg.Conflicts, base.Conflicts),
+			Replaces:   mergeValues("replaces", pkg.keys, pkg.Replaces, base.Replaces),
+			Backup:     pick(pkg.keys["backup"], pkg.Backup, base.Backup),
+			Options:    pick(pkg.keys["options"], pkg.Options, base.Options),
+			Install:    pick(pkg.keys["install"], pkg.Install, base.Install),
+			Changelog:  pick(pkg.keys["changelog"], pkg.Changelog, base.Changelog),
+		}
+		return merged, true
+	}
+	return Package{}, false
+}
+
+// Pkgnames retorna os nomes dos pacotes na ordem do .SRCINFO
+func (s *Srcinfo) Pkgnames() []string {
+	names := make([]string, 0, len(s.Packages))
+	for _, pkg := range s.Packages {
+		names = append(names, pkg.Pkgname)
+	}
+	return names
+}
+
+func pick[T any](overridden bool, value, base T) T {
+	if overridden {
+		return value
+	}
+	return base
+}
+
+// mergeValues aplica a sobrescrita chave a chave: "depends" e
+// "depends_x86_64" são independentes, como no makepkg
+func mergeValues(name string, keys map[string]bool, values, base []Value) []Value {
+	var merged []Value
+	var arches []string
+	seen := make(map[string]bool)
+	for _, v := range append(append([]Value{}, base...), values...) {
+		if !seen[v.Arch] {
+			seen[v.Arch] = true
+			arches = append(arches, v.Arch)
+		}
+	}
+	for key := range keys {
+		if n, arch := splitKey(key); n == name && !seen[arch] {
+			seen[arch] = true
+			arches = append(arches, arch)
+		}
+	}
+	for _, arch := range arches {
+		key := name
+		if arch != "" {
+			key += "_" + arch
+		}
+		from := base
+		if keys[key] {
+			from = values
+		}
+		for _, v := range from {
+			if v.Arch == arch {
+				merged = append(merged, v)
+			}
+		}
+	}
+	return merged
+}
+
+// Sources relaciona cada source com os checksums da mesma posição e da mesma
+// arquitetura (source_x86_64[i] com sha256sums_x86_64[i])
+func (s *Srcinfo) Sources() []Source {
+	var sources []Source
+	position := make(map[string]int) // próxima posição de cada arquitetura
+	for _, src := range s.Source {
+		i := position[src.Arch]
+		position[src.Arch]++
+
+		entry := Source{Arch: src.Arch, Source: src.Value, Checksums: make(map[string]string)}
+		entry.Filename, entry.URL = splitSource(src.Value)
+		for _, kind := range ChecksumKinds {
+			n := 0
+			for _, sum := range s.Checksums[kind] {
+				if sum.Arch != src.Arch {
+					continue
+				}
+				if n == i {
+					entry.Checksums[kind] = sum.Value
+					if sum.Value == "SKIP" {
+						entry.Skip = true
+					}
+					break
+				}
+				n++
+			}
+		}
+		sources = append(sources, entry)
+	}
+	return sources
+}
+
+// splitSource separa "nome::url" e deduz o nome do arquivo quando não há "::"
+func splitSource(source string) (filename, sourceURL string) {
+	if name, rest, ok := strings.Cut(source, "::"); ok {
+		return name, rest
+	}
+	if !strings.Contains(source, "://") {
+		return source, ""
+	}
+	base := source
+	if i := strings.IndexAny(base, "#?"); i >= 0 {
+		base = base[:i]
+	}
+	base = path.Base(strings.TrimSuffix(base, "/"))
+	if strings.HasPrefix(source, "git+") || strings.HasPrefix(source, "git://") {
+		base = strings.TrimSuffix(base, ".git")
+	}
+	return base, source
+}
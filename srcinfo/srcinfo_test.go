@@ -0,0 +1,155 @@
+package srcinfo
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const splitSrcinfo = `pkgbase = pipewire-git
+	pkgdesc = Low-latency audio/video router and processor
+	pkgver = 1.2.3.r45.gabcdef
+	pkgrel = 2
+	epoch = 1
+	url = https://pipewire.org
+	arch = x86_64
+	arch = aarch64
+	license = MIT
+	makedepends = git
+	makedepends = meson
+	makedepends_x86_64 = nasm
+	depends = glibc
+	depends_aarch64 = libarm
+	source = pipewire::git+https://gitlab.freedesktop.org/pipewire/pipewire.git#branch=master
+	source = pipewire.conf
+	source_x86_64 = https://example.org/blob-x86_64.tar.gz
+	sha256sums = SKIP
+	sha256sums = 9a0b8d7c6e5f4a3b2c1d0e9f8a7b6c5d4e3f2a1b0c9d8e7f6a5b4c3d2e1f0a9b
+	sha256sums_x86_64 = 0f1e2d3c4b5a69788796a5b4c3d2e1f00f1e2d3c4b5a69788796a5b4c3d2e1f0
+	b2sums = SKIP
+	b2sums = 1234
+	b2sums_x86_64 = 5678
+
+pkgname = pipewire-git
+	depends = glibc
+	depends = libpipewire-git
+	provides = pipewire
+
+pkgname = libpipewire-git
+	pkgdesc = Low-latency audio/video router and processor - client library
+	depends =
+	depends_aarch64 =
+	provides = libpipewire
+	provides = libpipewire-0.3.so=0-64
+`
+
+func TestParse(t *testing.T) {
+	info, err := ParseBytes([]byte(splitSrcinfo))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Pkgbase != "pipewire-git" || info.Version() != "1:1.2.3.r45.gabcdef-2" {
+		t.Errorf("pkgbase/versão = %q %q", info.Pkgbase, info.Version())
+	}
+	if got := strings.Join(info.Pkgnames(), " "); got != "pipewire-git libpipewire-git" {
+		t.Errorf("Pkgnames = %q", got)
+	}
+	if want := []Value{{"", "git"}, {"", "meson"}, {"x86_64", "nasm"}}; !reflect.DeepEqual(info.MakeDepends, want) {
+		t.Errorf("MakeDepends = %v", info.MakeDepends)
+	}
+
+	var tt = []struct {
+		pkgname  string
+		pkgdesc  string
+		depends  []Value
+		provides []Value
+	}{
+		{
+			"pipewire-git",
+			"Low-latency audio/video router and processor",
+			[]Value{{"", "glibc"}, {"", "libpipewire-git"}, {"aarch64", "libarm"}}, // depends_aarch64 herdado da base
+			[]Value{{"", "pipewire"}},
+		},
+		{
+			"libpipewire-git",
+			"Low-latency audio/video router and processor - client library",
+			nil, // "depends =" e "depends_aarch64 =" zeram o que vem da base
+			[]Value{{"", "libpipewire"}, {"", "libpipewire-0.3.so=0-64"}},
+		},
+	}
+	for _, tc := range tt {
+		pkg, ok := info.Package(tc.pkgname)
+		if !ok {
+			t.Fatalf("Package(%q) não encontrado", tc.pkgname)
+		}
+		if pkg.Pkgdesc != tc.pkgdesc {
+			t.Errorf("%s: pkgdesc = %q", tc.pkgname, pkg.Pkgdesc)
+		}
+		if !reflect.DeepEqual(pkg.Depends, tc.depends) {
+			t.Errorf("%s: depends = %v, esperava %v", tc.pkgname, pkg.Depends, tc.depends)
+		}
+		if !reflect.DeepEqual(pkg.Provides, tc.provides) {
+			t.Errorf("%s: provides = %v, esperava %v", tc.pkgname, pkg.Provides, tc.provides)
+		}
+		if strings.Join(pkg.Arch, " ") != "x86_64 aarch64" || strings.Join(pkg.License, " ") != "MIT" {
+			t.Errorf("%s: arch/license não herdados da base: %v %v", tc.pkgname, pkg.Arch, pkg.License)
+		}
+	}
+}
+
+func TestSources(t *testing.T) {
+	info, err := ParseBytes([]byte(splitSrcinfo))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var tt = []Source{
+		{
+			Source:    "pipewire::git+https://gitlab.freedesktop.org/pipewire/pipewire.git#branch=master",
+			Filename:  "pipewire",
+			URL:       "git+https://gitlab.freedesktop.org/pipewire/pipewire.git#branch=master",
+			Checksums: map[string]string{"sha256sums": "SKIP", "b2sums": "SKIP"},
+			Skip:      true,
+		},
+		{
+			Source:    "pipewire.conf",
+			Filename:  "pipewire.conf",
+			Checksums: map[string]string{"sha256sums": "9a0b8d7c6e5f4a3b2c1d0e9f8a7b6c5d4e3f2a1b0c9d8e7f6a5b4c3d2e1f0a9b", "b2sums": "1234"},
+		},
+		{
+			Arch:      "x86_64",
+			Source:    "https://example.org/blob-x86_64.tar.gz",
+			Filename:  "blob-x86_64.tar.gz",
+			URL:       "https://example.org/blob-x86_64.tar.gz",
+			Checksums: map[string]string{"sha256sums": "0f1e2d3c4b5a69788796a5b4c3d2e1f00f1e2d3c4b5a69788796a5b4c3d2e1f0", "b2sums": "5678"},
+		},
+	}
+	sources := info.Sources()
+	if len(sources) != len(tt) {
+		t.Fatalf("Sources = %d entradas, esperava %d", len(sources), len(tt))
+	}
+	for i, want := range tt {
+		if !reflect.DeepEqual(sources[i], want) {
+			t.Errorf("Sources[%d] = %+v, esperava %+v", i, sources[i], want)
+		}
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	var tt = []struct {
+		name  string
+		input string
+	}{
+		{"vazio", ""},
+		{"sem pkgbase", "pkgname = foo\n"},
+		{"chave antes do pkgbase", "pkgver = 1\npkgbase = foo\npkgname = foo\n"},
+		{"sem pkgname", "pkgbase = foo\n\tpkgver = 1\n"},
+		{"linha inválida", "pkgbase = foo\n\tpkgver 1\npkgname = foo\n"},
+		{"pkgver no pacote", "pkgbase = foo\npkgname = foo\n\tpkgver = 2\n"},
+		{"checksum no pacote", "pkgbase = foo\npkgname = foo\n\tsha256sums = SKIP\n"},
+	}
+	for _, tc := range tt {
+		if info, err := ParseBytes([]byte(tc.input)); err == nil {
+			t.Errorf("%s: Parse = %+v, esperava erro", tc.name, info)
+		}
+	}
+}
@@ -368,7 +368,13 @@ func prepareGettext(inputFile string) {
 	// Verificar se o arquivo .pot já existe
 	if _, err := os.Stat(potFile); os.IsNotExist(err) || forceFlag {
 		logger.Printf("%s Rodando xgettext em: %s\n", black("[XGETTEXT]"), magenta(inputFile))
-		cmd := exec.Command("xgettext", "--verbose", "--from-code=UTF-8", "--language=shell", "--keyword=gettext", "--output="+potFile, inputFile)
+		// Fontes Go (ex: big-search-aur) marcam as mensagens com gettext("..."),
+		// que o analisador de C do xgettext reconhece
+		language := "shell"
+		if strings.HasSuffix(inputFile, ".go") {
+			language = "C"
+		}
+		cmd := exec.Command("xgettext", "--verbose", "--from-code=UTF-8", "--language="+language, "--keyword=gettext", "--output="+potFile, inputFile)
 		cmd.Stdout = os.Stdout
 		cmd.Stderr = os.Stderr
 		if err := cmd.Run(); err != nil {
@@ -0,0 +1,3 @@
+module vercmp
+
+go 1.23.0
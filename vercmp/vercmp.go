@@ -0,0 +1,169 @@
+/*
+  vercmp.go - comparação de versões no formato do pacman (epoch:pkgver-pkgrel)
+    Chili GNU/Linux - https://github.com/vcatafesta/chili/go
+    Chili GNU/Linux - https://chililinux.com
+    Chili GNU/Linux - https://chilios.com.br
+
+  Created: 2026/10/17
+  Altered: 2026/10/17
+
+  Copyright (c) 2024-2026, Vilmar Catafesta <vcatafesta@gmail.com>
+  All rights reserved.
+
+  Redistribution and use in source and binary forms, with or without
+  modification, are permitted provided that the following conditions
+  are met:
+  1. Redistributions of source code must retain the above copyright
+    notice, this list of conditions and the following disclaimer.
+  2. Redistributions in binary form must reproduce the above copyright
+    notice, this list of conditions and the following disclaimer in the
+    documentation and/or other materials provided with the distribution.
+
+  THIS SOFTWARE IS PROVIDED BY THE AUTHOR ``AS IS'' AND ANY EXPRESS OR
+  IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED WARRANTIES
+  OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE DISCLAIMED.
+  IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR ANY DIRECT, INDIRECT,
+  INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING, BUT
+  NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE,
+  DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY
+  THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT
+  (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF
+  THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+*/
+
+// Package vercmp compara versões de pacotes exatamente como o vercmp do
+// pacman (alpm_pkg_vercmp): epoch, pkgver em blocos numéricos/alfabéticos e
+// pkgrel, que também pode ter ponto (1.0-1.1).
+//
+// Usado pelo big-search-aur e pelo big-vercmp; outros módulos do repositório
+// podem importá-lo com:
+//
+//	require vercmp v0.0.0
+//	replace vercmp => ../vercmp
+package vercmp
+
+import "strings"
+
+// Compare retorna -1 se a < b, 0 se a == b e 1 se a > b.
+func Compare(a, b string) int {
+	if a == b {
+		return 0
+	}
+	epoch1, ver1, rel1 := parseEVR(a)
+	epoch2, ver2, rel2 := parseEVR(b)
+
+	ret := rpmvercmp(epoch1, epoch2)
+	if ret == 0 {
+		ret = rpmvercmp(ver1, ver2)
+		if ret == 0 && rel1 != "" && rel2 != "" {
+			ret = rpmvercmp(rel1, rel2)
+		}
+	}
+	return ret
+}
+
+// parseEVR separa "epoch:versão-release". Sem epoch, assume "0"; o release é
+// o que vem depois do último '-'.
+func parseEVR(evr string) (epoch, version, release string) {
+	s := 0
+	for s < len(evr) && isDigit(evr[s]) {
+		s++
+	}
+	epoch, version = "0", evr
+	if s < len(evr) && evr[s] == ':' {
+		if s > 0 {
+			epoch = evr[:s]
+		}
+		version = evr[s+1:]
+	}
+	if i := strings.LastIndexByte(version, '-'); i >= 0 {
+		version, release = version[:i], version[i+1:]
+	}
+	return epoch, version, release
+}
+
+// rpmvercmp compara dois trechos de versão bloco a bloco, como a função de
+// mesmo nome da libalpm
+func rpmvercmp(a, b string) int {
+	if a == b {
+		return 0
+	}
+	one, two := 0, 0 // início do bloco atual em a e b
+	for one < len(a) && two < len(b) {
+		ptr1, ptr2 := one, two
+		for one < len(a) && !isAlnum(a[one]) {
+			one++
+		}
+		for two < len(b) && !isAlnum(b[two]) {
+			two++
+		}
+		if one >= len(a) || two >= len(b) {
+			break
+		}
+		// Separadores de tamanhos diferentes decidem a comparação
+		if one-ptr1 != two-ptr2 {
+			if one-ptr1 < two-ptr2 {
+				return -1
+			}
+			return 1
+		}
+
+		ptr1, ptr2 = one, two
+		isNum := isDigit(a[ptr1])
+		if isNum {
+			for ptr1 < len(a) && isDigit(a[ptr1]) {
+				ptr1++
+			}
+			for ptr2 < len(b) && isDigit(b[ptr2]) {
+				ptr2++
+			}
+		} else {
+			for ptr1 < len(a) && isAlpha(a[ptr1]) {
+				ptr1++
+			}
+			for ptr2 < len(b) && isAlpha(b[ptr2]) {
+				ptr2++
+			}
+		}
+
+		// Blocos de tipos diferentes: o numérico é o mais novo
+		if two == ptr2 {
+			if isNum {
+				return 1
+			}
+			return -1
+		}
+
+		seg1, seg2 := a[one:ptr1], b[two:ptr2]
+		if isNum {
+			seg1 = strings.TrimLeft(seg1, "0")
+			seg2 = strings.TrimLeft(seg2, "0")
+			if len(seg1) != len(seg2) {
+				if len(seg1) > len(seg2) {
+					return 1
+				}
+				return -1
+			}
+		}
+		if c := strings.Compare(seg1, seg2); c != 0 {
+			return c
+		}
+		one, two = ptr1, ptr2
+	}
+
+	if one >= len(a) && two >= len(b) {
+		return 0
+	}
+	// Um bloco alfabético que sobra nunca vence uma string vazia:
+	// - se a acabou e b não continua com letra, b é mais novo;
+	// - se a continua com letra, b é mais novo;
+	// - caso contrário, a é mais novo.
+	if (one >= len(a) && !isAlpha(b[two])) || (one < len(a) && isAlpha(a[one])) {
+		return -1
+	}
+	return 1
+}
+
+func isDigit(c byte) bool { return c >= '0' && c <= '9' }
+func isAlpha(c byte) bool { return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' }
+func isAlnum(c byte) bool { return isDigit(c) || isAlpha(c) }
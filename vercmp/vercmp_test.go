@@ -0,0 +1,103 @@
+package vercmp
+
+import "testing"
+
+// Casos do test/util/vercmptest.sh do pacman. Cada caso também é testado
+// invertido, esperando o resultado com o sinal trocado.
+var tt = []struct {
+	ver1     string
+	ver2     string
+	expected int
+}{
+	// mesmo tamanho, sem pkgrel
+	{"1.5.0", "1.5.0", 0},
+	{"1.5.1", "1.5.0", 1},
+
+	// tamanhos diferentes
+	{"1.5.1", "1.5", 1},
+
+	// com pkgrel, simples
+	{"1.5.0-1", "1.5.0-1", 0},
+	{"1.5.0-1", "1.5.0-2", -1},
+	{"1.5.0-1", "1.5.1-1", -1},
+	{"1.5.0-2", "1.5.1-1", -1},
+
+	// com pkgrel, tamanhos diferentes
+	{"1.5-1", "1.5.1-1", -1},
+	{"1.5-2", "1.5.1-1", -1},
+	{"1.5-2", "1.5.1-2", -1},
+
+	// pkgrel presente em apenas um dos lados
+	{"1.5", "1.5-1", 0},
+	{"1.5-1", "1.5", 0},
+	{"1.1-1", "1.1", 0},
+	{"1.0-1", "1.1", -1},
+	{"1.1-1", "1.0", 1},
+
+	// versões alfanuméricas
+	{"1.5b-1", "1.5-1", -1},
+	{"1.5b", "1.5", -1},
+	{"1.5b-1", "1.5", -1},
+	{"1.5b", "1.5.1", -1},
+
+	// exemplos da página de manual
+	{"1.0a", "1.0alpha", -1},
+	{"1.0alpha", "1.0b", -1},
+	{"1.0b", "1.0beta", -1},
+	{"1.0beta", "1.0rc", -1},
+	{"1.0rc", "1.0", -1},
+
+	// letras depois de ponto
+	{"1.5.a", "1.5", 1},
+	{"1.5.b", "1.5.a", 1},
+	{"1.5.1", "1.5.b", 1},
+
+	// letras depois de ponto, com pkgrel
+	{"1.5.b-1", "1.5.b", 0},
+	{"1.5-1", "1.5.b", -1},
+
+	// mesmo conteúdo, separadores diferentes
+	{"2.0", "2_0", 0},
+	{"2.0_a", "2_0.a", 0},
+	{"2.0a", "2.0.a", -1},
+	{"2___a", "2_a", 1},
+
+	// com epoch
+	{"0:1.0", "0:1.0", 0},
+	{"0:1.0", "0:1.1", -1},
+	{"1:1.0", "0:1.0", 1},
+	{"1:1.0", "0:1.1", 1},
+	{"1:1.0", "2:1.1", -1},
+
+	// epoch e pkgrel em apenas um dos lados
+	{"1:1.0", "0:1.0-1", 1},
+	{"1:1.0-1", "0:1.1-1", 1},
+
+	// epoch em apenas um dos lados
+	{"0:1.0", "1.0", 0},
+	{"0:1.0", "1.1", -1},
+	{"0:1.1", "1.0", 1},
+	{"1:1.0", "1.0", 1},
+	{"1:1.0", "1.1", 1},
+	{"1:1.1", "1.1", 1},
+
+	// pkgrel com ponto (rebuilds menores, como 1.0-1.1)
+	{"1.0-1", "1.0-1.1", -1},
+	{"1.0-1.1", "1.0-1.2", -1},
+	{"1.0-2", "1.0-1.1", 1},
+	{"1.0-1.1", "1.0-1.1", 0},
+
+	// o exemplo que motivou o pacote
+	{"1:2.0-1", "1.9.9-3", 1},
+}
+
+func TestCompare(t *testing.T) {
+	for _, tc := range tt {
+		if got := Compare(tc.ver1, tc.ver2); got != tc.expected {
+			t.Errorf("Compare(%q, %q) = %d, esperava %d", tc.ver1, tc.ver2, got, tc.expected)
+		}
+		if got := Compare(tc.ver2, tc.ver1); got != -tc.expected {
+			t.Errorf("Compare(%q, %q) = %d, esperava %d", tc.ver2, tc.ver1, got, -tc.expected)
+		}
+	}
+}